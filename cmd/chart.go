@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+// chartColors are the colors of the lines of a chart, in order.
+var chartColors = []string{"#e6194b", "#3cb44b", "#ffe119", "#4363d8", "#f58231", "#911eb4", "#46f0f0", "#f032e6", "#bcf60c", "#fabebe", "#008080", "#e6beff"}
+
+// chartSeries is a line of a score chart, its points are sorted by date.
+type chartSeries struct {
+	Name   string
+	Points []ctfd.ScorePoint
+	Bold   bool // highlight the line, for our own team
+}
+
+// scoreAt returns the score of the series at a time, the score of its last
+// point before it.
+func (s chartSeries) scoreAt(t time.Time) int {
+	score := 0
+	for _, point := range s.Points {
+		if point.Date.After(t) {
+			break
+		}
+		score = point.Score
+	}
+	return score
+}
+
+// windowTimeline returns the points of the timeline between from and to. A
+// point at from carries the score reached before the window.
+func windowTimeline(timeline []ctfd.ScorePoint, from, to time.Time) []ctfd.ScorePoint {
+	var points []ctfd.ScorePoint
+	before := 0
+	for _, point := range timeline {
+		switch {
+		case point.Date.Before(from):
+			before = point.Score
+		case !point.Date.After(to):
+			points = append(points, point)
+		}
+	}
+
+	if before > 0 {
+		points = append([]ctfd.ScorePoint{{Date: from, Score: before}}, points...)
+	}
+
+	return points
+}
+
+// chartRange returns the time range and the highest score of the series.
+func chartRange(series []chartSeries) (from, to time.Time, maxScore int) {
+	for _, s := range series {
+		for _, point := range s.Points {
+			if from.IsZero() || point.Date.Before(from) {
+				from = point.Date
+			}
+			if point.Date.After(to) {
+				to = point.Date
+			}
+			if point.Score > maxScore {
+				maxScore = point.Score
+			}
+		}
+	}
+	return from, to, maxScore
+}
+
+// brailleDots are the bits of the dots of a braille character, by column and row.
+var brailleDots = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// brailleChart draws the series as step lines on a braille canvas of width by
+// height characters, between from and to, with the score and time axes and
+// a legend. Every character holds 2 by 4 dots.
+func brailleChart(series []chartSeries, from, to time.Time, width, height int) string {
+	_, _, maxScore := chartRange(series)
+	if maxScore == 0 {
+		maxScore = 1
+	}
+
+	label := strconv.Itoa(maxScore)
+	cols := width - len(label) - 2
+	if cols < 10 {
+		cols = 10
+	}
+	if height < 2 {
+		height = 2
+	}
+
+	dotsX, dotsY := cols*2, height*4
+	cells := make([][]rune, height)
+	colors := make([][]int, height)
+	for row := range cells {
+		cells[row] = make([]rune, cols)
+		colors[row] = make([]int, cols)
+		for col := range colors[row] {
+			colors[row][col] = -1
+		}
+	}
+
+	set := func(x, y, color int) {
+		row, col := (dotsY-1-y)/4, x/2
+		cells[row][col] |= brailleDots[x%2][(dotsY-1-y)%4]
+		colors[row][col] = color
+	}
+
+	span := to.Sub(from)
+	for i, s := range series {
+		previous := -1
+		for x := 0; x < dotsX; x++ {
+			t := to
+			if dotsX > 1 {
+				t = from.Add(time.Duration(float64(span) * float64(x) / float64(dotsX-1)))
+			}
+			y := int(math.Round(float64(s.scoreAt(t)) / float64(maxScore) * float64(dotsY-1)))
+			if y < 0 {
+				y = 0
+			}
+
+			// vertical step from the score of the previous column
+			low, high := y, y
+			if previous >= 0 && previous < y {
+				low = previous
+			}
+			if previous > y {
+				high = previous
+			}
+			for dy := low; dy <= high; dy++ {
+				set(x, dy, i)
+			}
+			previous = y
+		}
+	}
+
+	var b strings.Builder
+	for row := range cells {
+		axis := strings.Repeat(" ", len(label))
+		switch row {
+		case 0:
+			axis = label
+		case height - 1:
+			axis = fmt.Sprintf("%*d", len(label), 0)
+		}
+		fmt.Fprintf(&b, "%s ┤", axis)
+
+		for col, cell := range cells[row] {
+			char := string(0x2800 + cell)
+			if cell == 0 {
+				char = " "
+			} else if color := colors[row][col]; color >= 0 {
+				style := lipgloss.NewStyle().Foreground(lipgloss.Color(chartColors[color%len(chartColors)]))
+				char = style.Bold(series[color].Bold).Render(char)
+			}
+			b.WriteString(char)
+		}
+		b.WriteString("\n")
+	}
+
+	start, end := from.Local().Format("Jan 02 15:04"), to.Local().Format("Jan 02 15:04")
+	fmt.Fprintf(&b, "%s └%s\n", strings.Repeat(" ", len(label)), strings.Repeat("─", cols))
+	gap := cols - len(start) - len(end)
+	if gap < 1 {
+		gap = 1
+	}
+	fmt.Fprintf(&b, "%s  %s%s%s\n\n", strings.Repeat(" ", len(label)), start, strings.Repeat(" ", gap), end)
+
+	for i, s := range series {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(chartColors[i%len(chartColors)])).Bold(s.Bold)
+		score := 0
+		if len(s.Points) > 0 {
+			score = s.Points[len(s.Points)-1].Score
+		}
+		fmt.Fprintf(&b, "%s %s %d\n", style.Render("■"), style.Render(s.Name), score)
+	}
+
+	return b.String()
+}
+
+// svgChart draws the series as step lines in an SVG image of width by
+// height pixels, between from and to, with the score and time axes and a
+// legend.
+func svgChart(series []chartSeries, from, to time.Time, width, height int) string {
+	_, _, maxScore := chartRange(series)
+	if maxScore == 0 {
+		maxScore = 1
+	}
+
+	const left, right, top, bottom = 60, 200, 20, 40
+	plotWidth, plotHeight := float64(width-left-right), float64(height-top-bottom)
+	span := to.Sub(from)
+
+	x := func(t time.Time) float64 {
+		if span <= 0 {
+			return left
+		}
+		return left + plotWidth*float64(t.Sub(from))/float64(span)
+	}
+	y := func(score int) float64 {
+		return top + plotHeight - plotHeight*float64(score)/float64(maxScore)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	fmt.Fprintf(&b, `<path d="M%d %d V%.1f H%.1f" fill="none" stroke="black"/>`+"\n", left, top, top+plotHeight, left+plotWidth)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n", left-5, top+4, maxScore)
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">0</text>`+"\n", left-5, top+plotHeight+4)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", left, height-15, html.EscapeString(from.UTC().Format("2006-01-02 15:04 UTC")))
+	fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="end">%s</text>`+"\n", left+plotWidth, height-15, html.EscapeString(to.UTC().Format("2006-01-02 15:04 UTC")))
+
+	for i, s := range series {
+		color := chartColors[i%len(chartColors)]
+		strokeWidth := 1.5
+		if s.Bold {
+			strokeWidth = 3
+		}
+
+		// steps from 0 at the start of the window to the score at its end
+		path := fmt.Sprintf("M%.1f %.1f", x(from), y(0))
+		for _, point := range s.Points {
+			path += fmt.Sprintf(" H%.1f V%.1f", x(point.Date), y(point.Score))
+		}
+		path += fmt.Sprintf(" H%.1f", x(to))
+
+		fmt.Fprintf(&b, `<path d="%s" fill="none" stroke="%s" stroke-width="%.1f"/>`+"\n", path, color, strokeWidth)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%d" width="10" height="10" fill="%s"/>`+"\n", left+plotWidth+15, top+i*18, color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%s</text>`+"\n", left+plotWidth+30, top+i*18+10, html.EscapeString(s.Name))
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
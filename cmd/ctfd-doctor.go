@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/ritchies/ctftool/pkg/scraper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorJSON bool
+
+// ctfdDoctorCmd represents the doctor command
+var ctfdDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose a CTFd instance and the configuration",
+	Long: `Check a CTFd instance and the configuration used to reach it.
+
+Reports the CTFd version, user or team mode, whether the challenges are
+visible, whether the CTF has started, ended or is paused, whether the
+credentials are valid, the clock skew with the server, rate limiting and
+TLS details. Every check has a pass, warn or fail verdict, the command
+exits with an error when a check fails.`,
+	Example: `  ctftool ctfd doctor --url https://demo.ctfd.io --token <token>
+  ctftool ctfd doctor --url https://demo.ctfd.io --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ctfd.NewClient()
+		ctfdOptions()
+
+		client.BaseURL = getBaseURL(cmd)
+		client.Creds = &scraper.Credentials{
+			Username: opts.Username,
+			Password: opts.Password,
+			Token:    opts.Token,
+		}
+
+		if doctorJSON {
+			outputFormat = "json"
+		}
+
+		diagnostics := diagnosticList(ctfd.Diagnose())
+		printResult(diagnostics)
+
+		for _, diagnostic := range diagnostics {
+			if diagnostic.Verdict == ctfd.Fail {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// diagnosticList is the output of ctfd doctor.
+type diagnosticList []ctfd.Diagnostic
+
+func (l diagnosticList) Columns() []string {
+	return []string{"check", "verdict", "details"}
+}
+
+func (l diagnosticList) Rows() [][]string {
+	var rows [][]string
+	for _, diagnostic := range l {
+		rows = append(rows, []string{diagnostic.Check, strings.ToUpper(string(diagnostic.Verdict)), diagnostic.Message})
+	}
+	return rows
+}
+
+func init() {
+	ctfdCmd.AddCommand(ctfdDoctorCmd)
+
+	ctfdDoctorCmd.Flags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	ctfdDoctorCmd.Flags().StringVarP(&opts.Username, "username", "u", "", "Username for CTFd authentication")
+	ctfdDoctorCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	ctfdDoctorCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
+	ctfdDoctorCmd.Flags().BoolVarP(&doctorJSON, "json", "", false, "Print the results as JSON")
+	CheckErr(ctfdDoctorCmd.Flags().MarkDeprecated("json", "use --output json instead"))
+
+	// viper
+	err := viper.BindPFlag("url", ctfdDoctorCmd.Flags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", ctfdDoctorCmd.Flags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", ctfdDoctorCmd.Flags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("token", ctfdDoctorCmd.Flags().Lookup("token"))
+	CheckErr(err)
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path"
 	"strings"
 	"sync"
 	"time"
@@ -49,102 +48,120 @@ func runDownload(cmd *cobra.Command, args []string) {
 		log.Infof("Authenticated as %q", opts.Username)
 	}
 
-	processChallenges()
+	checkIndexFormats(cmd)
+
+	layout := challengeLayout(cmd)
+	filter := challengeFilter(cmd, layout)
+
+	if opts.DryRun {
+		challenges, err := ctfd.ListChallenges()
+		CheckErr(err)
+		printPlan(planChallenges(challenges, layout, filter, downloadAction))
+		return
+	}
+
+	report, err := processChallenges(layout, filter)
+	printRun(report, err)
 
 	if opts.SaveConfig {
 		saveConfig()
 	}
 
 	if opts.Watch {
-		watch(processChallenges)
+		watch("new challenges", func() {
+			// a temporary error must not stop watching
+			report, err := processChallenges(layout, filter)
+			if err != nil {
+				log.Warnf("Checking challenges failed: %v", err)
+			}
+			if report != nil {
+				log.Infof("Checked challenges: %s", report.Summary())
+			}
+		})
+	}
+
+	if code := runExitCode(report, err); code != 0 {
+		os.Exit(code)
 	}
 }
 
-func processChallenges() {
-	rl := GetRateLimit()
-	var wg sync.WaitGroup
+// checkIndexFormats makes sure every --index-format is supported before anything is downloaded.
+func checkIndexFormats(cmd *cobra.Command) {
+	for _, format := range opts.IndexFormats {
+		valid := false
+		for _, known := range ctfd.IndexFormats {
+			if strings.EqualFold(format, known) {
+				valid = true
+			}
+		}
 
+		if !valid {
+			ShowHelp(cmd, fmt.Sprintf("Invalid --index-format %q, expected one of %s", format, strings.Join(ctfd.IndexFormats, ", ")))
+		}
+	}
+}
+
+// downloadAction skips challenges that were already downloaded, unless --overwrite is set.
+func downloadAction(challengePath string) (string, string) {
+	if _, err := os.Stat(challengePath); err == nil {
+		if !opts.Overwrite {
+			return actionSkip, "already downloaded, use --overwrite"
+		}
+		return actionUpdate, "overwrite is set"
+	}
+
+	return actionCreate, ""
+}
+
+// processChallenges downloads the challenges selected by the filter. Failed
+// challenges are collected in the report and don't stop the others, the error
+// is set when the challenges can't be listed or the index can't be written.
+func processChallenges(layout *ctfd.Layout, filter *ctfd.Filter) (*runReport, error) {
 	// List challenges
 	challenges, err := ctfd.ListChallenges()
-	CheckErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	// Setup challenge notifications
+	var mu sync.Mutex
 	notifications := ChallengeNotifications{
 		Categories: make(map[string]int),
 	}
 
-	for _, challenge := range SortChallenges(challenges) {
-		if opts.UnsolvedOnly && challenge.SolvedByMe {
-			log.Debugf("Skipping %d : already solved", challenge.ID)
-			continue
-		}
-
-		name := lib.CleanSlug(challenge.Name, false)
-		category := strings.Split(challenge.Category, " ")[0]
-		category = lib.CleanSlug(category, true)
+	report := newRunReport()
+	plan := pendingChallenges(planChallenges(challenges, layout, filter, downloadAction), report)
 
-		if len(category) < 1 || len(name) < 1 {
-			log.Debugf("Skipping %d : invalid name or category", challenge.ID)
-			continue
-		}
-
-		challengePath := path.Join(opts.Output, category, name)
-
-		if _, statErr := os.Stat(challengePath); statErr == nil {
-			if !opts.Overwrite {
-				log.Debugf("Skipping %d : overwrite is false", challenge.ID)
-				continue
-			}
-		}
+	ctfd.SetDownloadConcurrency(opts.FileConcurrency)
+	progress := newProgress("Downloading", len(plan))
 
-		wg.Add(1)
+	forEachChallenge(plan, opts.Concurrency, func(planned plannedChallenge) {
+		defer progress.ChallengeDone(fmt.Sprintf("%s/%s", planned.Category, planned.Name))
 
-		if options.RateLimit > 0 {
-			rl.Take()
+		if err := downloadChallenge(planned); err != nil {
+			report.fail(planned, err)
+			removePartial(planned)
+			return
 		}
 
-		go func(challenge ctfd.ChallengesData) {
-			log.WithField("challenge", fmt.Sprintf("%s/%s", category, name)).Infof("Downloading challenge %d", challenge.ID)
-
-			chall, err := ctfd.Challenge(challenge.ID)
-			CheckWarn(err)
-			if err != nil {
-				wg.Done()
-				return
-			}
-
-			err = os.MkdirAll(challengePath, os.ModePerm)
-			CheckErr(err)
-
-			// download challenge files
-			err = ctfd.DownloadFiles(chall.Files, challengePath)
-			CheckWarn(err)
-
-			if len(chall.Files) > 0 && err != nil {
-				log.Debugf("Skipping challenge %d : error downloading files", challenge.ID)
-				err = os.RemoveAll(challengePath)
-				CheckWarn(err)
-				wg.Done()
-				return
-			}
+		report.add(planned, completedStatus(planned, "downloaded"), "")
 
-			// get description
-			err = ctfd.GetDescription(chall, challengePath)
-			CheckErr(err)
+		mu.Lock()
+		notifications.Total++
+		notifications.Categories[planned.Category]++
+		mu.Unlock()
+	})
 
-			// Add challenge to notifications, probably should make sure to lock?
-			notifications.Total++
-			notifications.Categories[category]++
+	progress.Close()
 
-			wg.Done()
-		}(challenge)
+	if err := layout.Save(opts.Output, challenges); err != nil {
+		return report, err
 	}
 
-	wg.Wait()
-
 	// Generate Index
-	err = ctfd.GenerateIndex(challenges, opts.Output)
-	CheckErr(err)
+	if err := ctfd.GenerateIndex(challenges, layout, opts.Output, opts.IndexFormats...); err != nil {
+		return report, fmt.Errorf("failed to generate index: %v", err)
+	}
 
 	if opts.Notify && notifications.Total > 0 {
 		builder := strings.Builder{}
@@ -157,19 +174,44 @@ func processChallenges() {
 		err := lib.SendNotification("CTFTool", builder.String())
 		CheckWarn(err)
 	}
+
+	return report, nil
 }
 
-func watch(processFunc func()) {
+// downloadChallenge downloads the files of a challenge and writes its writeup template.
+func downloadChallenge(planned plannedChallenge) error {
+	log.WithField("challenge", fmt.Sprintf("%s/%s", planned.Category, planned.Name)).Infof("Downloading challenge %d", planned.Challenge.ID)
+
+	chall, err := ctfd.Challenge(planned.Challenge.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(planned.Path, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create challenge folder: %v", err)
+	}
+
+	// download challenge files
+	if err := ctfd.DownloadFiles(chall.Files, planned.Path); err != nil {
+		return err
+	}
+
+	// get description
+	return ctfd.GetDescription(chall, planned.Path)
+}
+
+// watch runs processFunc every --watch-interval, subject is what is watched.
+func watch(subject string, processFunc func()) {
 	interval, err := time.ParseDuration(opts.WatchInterval.String())
 	CheckErr(err)
 
-	log.Infof("Watching for new challenges every %s", interval.String())
+	log.Infof("Watching %s every %s", subject, interval.String())
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		log.Debugf("Checking for new challenges")
+		log.Debugf("Checking %s", subject)
 		processFunc()
 	}
 }
@@ -189,6 +231,11 @@ func init() {
 	ctfdDownloadCmd.Flags().BoolVarP(&opts.Overwrite, "overwrite", "", false, "Overwrite existing files")
 	ctfdDownloadCmd.Flags().Int64VarP(&opts.MaxFileSize, "max-file-size", "", 25, "Maximum allowable file size in MB")
 	ctfdDownloadCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+	addConcurrencyFlag(ctfdDownloadCmd)
+	ctfdDownloadCmd.Flags().IntVarP(&opts.FileConcurrency, "file-concurrency", "", ctfd.DefaultDownloadConcurrency, "Number of files downloaded at the same time, across all challenges")
+	addReportFlag(ctfdDownloadCmd)
+	ctfdDownloadCmd.Flags().StringSliceVarP(&opts.IndexFormats, "index-format", "", []string{"md"}, fmt.Sprintf("Formats of the challenge index (%s)", strings.Join(ctfd.IndexFormats, "|")))
+	addFilterFlags(ctfdDownloadCmd)
 
 	// viper
 	err := viper.BindPFlag("url", ctfdDownloadCmd.Flags().Lookup("url"))
@@ -226,4 +273,16 @@ func init() {
 
 	err = viper.BindPFlag("skip-check", ctfdDownloadCmd.Flags().Lookup("skip-check"))
 	CheckErr(err)
+
+	err = viper.BindPFlag("path-template", ctfdDownloadCmd.Flags().Lookup("path-template"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("concurrency", ctfdDownloadCmd.Flags().Lookup("concurrency"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("file-concurrency", ctfdDownloadCmd.Flags().Lookup("file-concurrency"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("index-format", ctfdDownloadCmd.Flags().Lookup("index-format"))
+	CheckErr(err)
 }
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	listSort  string // sort key of the challenge list
+	listDecay bool   // project the value of the dynamic challenges
+
+	// listSortKeys are the sort keys of ctfd list, priority is the order of download
+	listSortKeys = []string{"priority", "id", "name", "category", "value", "solves"}
+)
+
+// challengeRow is a challenge as printed by ctfd list.
+type challengeRow struct {
+	ID       int64    `json:"id" yaml:"id"`
+	Category string   `json:"category" yaml:"category"`
+	Name     string   `json:"name" yaml:"name"`
+	Value    int64    `json:"value" yaml:"value"`
+	Solves   int64    `json:"solves" yaml:"solves"`
+	Solved   bool     `json:"solved" yaml:"solved"`
+	Tags     []string `json:"tags" yaml:"tags"`
+
+	// value if solved now and points lost to the next solve, for the unsolved
+	// dynamic challenges with --decay
+	Expected *int64 `json:"expected,omitempty" yaml:"expected,omitempty"`
+	Loss     *int64 `json:"loss,omitempty" yaml:"loss,omitempty"`
+}
+
+// ctfdListCmd represents the list command
+var ctfdListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the challenges",
+	Long: `List the challenges of a CTFd instance with their value, solves and
+whether they are solved by you. The challenges are sorted like download
+processes them, unsolved challenges with the fewest solves first, unless
+--sort is set. The filters of download are supported.
+
+The table leaves out the tags, the other formats of --output include them.
+
+--decay requests the unsolved dynamic challenges to add the value they are
+worth if solved now and the points lost if another team solves them first.`,
+	Example: `  ctftool ctfd list --url https://demo.ctfd.io --token abcdef12356
+  ctftool ctfd list --unsolved --category web --sort value
+  ctftool ctfd list --output json | jq '.[] | select(.solves == 0)'
+  ctftool ctfd list --unsolved --decay --sort value`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ctfd.NewClient()
+		ctfdOptions()
+
+		if !contains(listSortKeys, listSort) {
+			ShowHelp(cmd, fmt.Sprintf("Invalid --sort %q, expected one of %s", listSort, strings.Join(listSortKeys, ", ")))
+		}
+
+		client.BaseURL = getBaseURL(cmd)
+		client.Creds = getCredentials(cmd)
+
+		if !opts.SkipCTFDCheck {
+			CheckErr(ctfd.Check())
+		}
+
+		if (opts.Username != "" || opts.Password != "") && opts.Token == "" {
+			err := ctfd.Authenticate()
+			CheckErr(err)
+			log.Infof("Authenticated as %q", opts.Username)
+		}
+
+		// the layout only adds the category aliases to the filter
+		layout, err := ctfd.NewLayout("", viper.GetStringMapString("category-aliases"))
+		CheckErr(err)
+		filter := challengeFilter(cmd, layout)
+
+		challenges, err := ctfd.ListChallenges()
+		CheckErr(err)
+
+		rows := challengeList{}
+		for _, challenge := range sortChallengesBy(challenges, listSort) {
+			if ok, _ := filter.Match(challenge); !ok {
+				continue
+			}
+
+			row := newChallengeRow(challenge)
+			if listDecay && challenge.Type == "dynamic" && !challenge.SolvedByMe {
+				projectChallengeRow(&row)
+			}
+			rows = append(rows, row)
+		}
+
+		printResult(rows)
+	},
+}
+
+func newChallengeRow(challenge ctfd.ChallengesData) challengeRow {
+	row := challengeRow{
+		ID:       challenge.ID,
+		Category: challenge.Category,
+		Name:     challenge.Name,
+		Value:    challenge.Value,
+		Solves:   challenge.Solves,
+		Solved:   challenge.SolvedByMe,
+		Tags:     []string{},
+	}
+
+	for _, tag := range challenge.Tags {
+		row.Tags = append(row.Tags, tag.Value)
+	}
+
+	return row
+}
+
+// projectChallengeRow adds the projection of the value to the row of a
+// dynamic challenge, the list of challenges lacks the decay.
+func projectChallengeRow(row *challengeRow) {
+	challenge, err := ctfd.Challenge(row.ID)
+	if err != nil {
+		log.Warnf("Failed to get the decay of %q: %v", row.Name, err)
+		return
+	}
+
+	if projection := projectChallenge(challenge); projection != nil {
+		row.Expected, row.Loss = &projection.Expected, &projection.Loss
+	}
+}
+
+// sortChallengesBy sorts the challenges by a key of listSortKeys, ties are broken by ID.
+func sortChallengesBy(challenges []ctfd.ChallengesData, key string) []ctfd.ChallengesData {
+	if key == "priority" {
+		return SortChallenges(challenges)
+	}
+
+	less := map[string]func(a, b ctfd.ChallengesData) bool{
+		"id":       func(a, b ctfd.ChallengesData) bool { return false },
+		"name":     func(a, b ctfd.ChallengesData) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) },
+		"category": func(a, b ctfd.ChallengesData) bool { return strings.ToLower(a.Category) < strings.ToLower(b.Category) },
+		"value":    func(a, b ctfd.ChallengesData) bool { return a.Value < b.Value },
+		"solves":   func(a, b ctfd.ChallengesData) bool { return a.Solves < b.Solves },
+	}[key]
+
+	sort.SliceStable(challenges, func(i, j int) bool {
+		if less(challenges[i], challenges[j]) {
+			return true
+		}
+		if less(challenges[j], challenges[i]) {
+			return false
+		}
+		return challenges[i].ID < challenges[j].ID
+	})
+
+	return challenges
+}
+
+// challengeList is the output of ctfd list.
+type challengeList []challengeRow
+
+func (l challengeList) Columns() []string {
+	columns := []string{"id", "category", "name", "value", "solves", "solved", "tags"}
+	if l.projected() {
+		columns = append(columns, "expected", "loss")
+	}
+	return columns
+}
+
+func (l challengeList) Rows() [][]string {
+	var rows [][]string
+	for _, row := range l {
+		record := []string{
+			strconv.FormatInt(row.ID, 10),
+			row.Category,
+			row.Name,
+			strconv.FormatInt(row.Value, 10),
+			strconv.FormatInt(row.Solves, 10),
+			strconv.FormatBool(row.Solved),
+			strings.Join(row.Tags, ";"),
+		}
+		if l.projected() {
+			record = append(record, optionalInt(row.Expected), optionalInt(row.Loss))
+		}
+		rows = append(rows, record)
+	}
+	return rows
+}
+
+// projected reports whether a challenge of the list has a projection of its value.
+func (l challengeList) projected() bool {
+	for _, row := range l {
+		if row.Expected != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// optionalInt formats the value, or an empty string when it is unset.
+func optionalInt(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
+
+// Render prints the challenges as a table, without their tags.
+func (l challengeList) Render(w io.Writer) error {
+	projected := l.projected()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 4, ' ', 0)
+	header := "ID\tCATEGORY\tNAME\tVALUE\tSOLVES\tSOLVED"
+	if projected {
+		header += "\tEXPECTED\tLOSS"
+	}
+	fmt.Fprintln(tw, header)
+
+	for _, row := range l {
+		solved := "❌"
+		if row.Solved {
+			solved = "✅"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%s", row.ID, row.Category, row.Name, row.Value, row.Solves, solved)
+		if projected && row.Expected != nil {
+			fmt.Fprintf(tw, "\t%d\t-%d", *row.Expected, *row.Loss)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	return tw.Flush()
+}
+
+func init() {
+	ctfdCmd.AddCommand(ctfdListCmd)
+
+	ctfdListCmd.Flags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	ctfdListCmd.Flags().StringVarP(&opts.Username, "username", "u", "", "Username for CTFd authentication")
+	ctfdListCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	ctfdListCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
+	ctfdListCmd.Flags().BoolVarP(&opts.UnsolvedOnly, "unsolved", "", false, "Only list challenges that haven't been solved yet")
+	ctfdListCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+	ctfdListCmd.Flags().StringVarP(&listSort, "sort", "", "priority", fmt.Sprintf("Sort key (%s)", strings.Join(listSortKeys, "|")))
+	ctfdListCmd.Flags().BoolVarP(&listDecay, "decay", "", false, "Show the value of the unsolved dynamic challenges if solved now and the points lost by waiting")
+	addChallengeFilterFlags(ctfdListCmd)
+
+	// viper
+	err := viper.BindPFlag("url", ctfdListCmd.Flags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", ctfdListCmd.Flags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", ctfdListCmd.Flags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("token", ctfdListCmd.Flags().Lookup("token"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("unsolved", ctfdListCmd.Flags().Lookup("unsolved"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("skip-check", ctfdListCmd.Flags().Lookup("skip-check"))
+	CheckErr(err)
+}
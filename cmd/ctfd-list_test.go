@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"gopkg.in/yaml.v3"
+)
+
+func listChallenges() []ctfd.ChallengesData {
+	return []ctfd.ChallengesData{
+		{ID: 1, Name: "Cookies", Category: "web", Value: 200, Solves: 10, SolvedByMe: true},
+		{ID: 2, Name: "baby rop", Category: "pwn", Value: 300, Solves: 0},
+		{ID: 3, Name: "XSS me", Category: "web", Value: 100, Solves: 40},
+		{ID: 4, Name: "=cmd", Category: "misc", Value: 100, Solves: 5, Tags: []ctfd.Tag{{Value: "easy"}}},
+	}
+}
+
+func TestSortChallengesBy(t *testing.T) {
+	tests := []struct {
+		description string
+		key         string
+		want        []int64
+	}{
+		{description: "priority", key: "priority", want: []int64{2, 4, 3, 1}},
+		{description: "id", key: "id", want: []int64{1, 2, 3, 4}},
+		{description: "name ignores case", key: "name", want: []int64{4, 2, 1, 3}},
+		{description: "category", key: "category", want: []int64{4, 2, 1, 3}},
+		{description: "value breaks ties by ID", key: "value", want: []int64{3, 4, 1, 2}},
+		{description: "solves", key: "solves", want: []int64{2, 4, 1, 3}},
+	}
+
+	for _, test := range tests {
+		var got []int64
+		for _, challenge := range sortChallengesBy(listChallenges(), test.key) {
+			got = append(got, challenge.ID)
+		}
+
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.description, got, test.want)
+		}
+	}
+}
+
+func TestChallengeList(t *testing.T) {
+	rows := challengeList{}
+	for _, challenge := range listChallenges() {
+		rows = append(rows, newChallengeRow(challenge))
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeResult(&buf, "json", "", rows); err != nil {
+			t.Fatalf("writeResult() returned error: %v", err)
+		}
+
+		var decoded challengeList
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("output is not valid JSON: %v", err)
+		}
+
+		if !cmp.Equal(decoded, rows) {
+			t.Errorf("got %+v, want %+v", decoded, rows)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeResult(&buf, "yaml", "", rows); err != nil {
+			t.Fatalf("writeResult() returned error: %v", err)
+		}
+
+		var decoded challengeList
+		if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("output is not valid YAML: %v", err)
+		}
+
+		if !cmp.Equal(decoded, rows) {
+			t.Errorf("got %+v, want %+v", decoded, rows)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeResult(&buf, "csv", "", rows); err != nil {
+			t.Fatalf("writeResult() returned error: %v", err)
+		}
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to read CSV: %v", err)
+		}
+
+		if want := []string{"4", "misc", "'=cmd", "100", "5", "false", "easy"}; !cmp.Equal(records[4], want) {
+			t.Errorf("got %v, want %v", records[4], want)
+		}
+	})
+
+	t.Run("empty json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeResult(&buf, "json", "", challengeList{}); err != nil {
+			t.Fatalf("writeResult() returned error: %v", err)
+		}
+
+		if got := strings.TrimSpace(buf.String()); got != "[]" {
+			t.Errorf("got %q, want an empty array", got)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeResult(&buf, "table", "", rows); err != nil {
+			t.Fatalf("writeResult() returned error: %v", err)
+		}
+
+		if !strings.HasPrefix(buf.String(), "ID") || !strings.Contains(buf.String(), "Cookies") {
+			t.Errorf("unexpected table:\n%s", buf.String())
+		}
+	})
+}
+
+func TestChallengeList_Projected(t *testing.T) {
+	rows := challengeList{}
+	for _, challenge := range listChallenges() {
+		rows = append(rows, newChallengeRow(challenge))
+	}
+
+	if columns := rows.Columns(); columns[len(columns)-1] == "loss" {
+		t.Error("got the projection columns without a projected challenge")
+	}
+
+	expected, loss := int64(280), int64(12)
+	rows[1].Expected, rows[1].Loss = &expected, &loss
+
+	if want := []string{"id", "category", "name", "value", "solves", "solved", "tags", "expected", "loss"}; !cmp.Equal(rows.Columns(), want) {
+		t.Errorf("got columns %v, want %v", rows.Columns(), want)
+	}
+
+	records := rows.Rows()
+	if want := []string{"2", "pwn", "baby rop", "300", "0", "false", "", "280", "12"}; !cmp.Equal(records[1], want) {
+		t.Errorf("got %v, want %v", records[1], want)
+	}
+	if got := records[0][7:]; !cmp.Equal(got, []string{"", ""}) {
+		t.Errorf("got %v for a static challenge, want empty fields", got)
+	}
+}
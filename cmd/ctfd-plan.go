@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ritchies/ctftool/internal/lib"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	actionCreate = "create"
+	actionUpdate = "update"
+	actionSkip   = "skip"
+)
+
+// plannedChallenge is what download and writeups will do with a challenge.
+type plannedChallenge struct {
+	Challenge ctfd.ChallengesData
+	Category  string
+	Name      string
+	Path      string
+	Action    string
+	Reason    string
+}
+
+// addFilterFlags adds the challenge filters, --path-template and --dry-run to a command.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&opts.PathTemplate, "path-template", "", "", fmt.Sprintf("Folder of every challenge (default %q)", ctfd.DefaultPathTemplate))
+	addChallengeFilterFlags(cmd)
+	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "", false, "Print the planned actions without touching the disk")
+}
+
+// addChallengeFilterFlags adds the challenge filters to a command.
+func addChallengeFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(&opts.Categories, "category", "c", nil, "Only challenges of these categories, raw or slugged (repeatable)")
+	cmd.Flags().Int64SliceVarP(&opts.IDs, "id", "", nil, "Only challenges with these IDs (repeatable)")
+	cmd.Flags().StringVarP(&opts.NameRegex, "name", "", "", "Only challenges with a name matching this regex")
+	cmd.Flags().StringVarP(&opts.Values, "value", "", "", "Only challenges worth this many points, as 'min-max', 'min-' or '-max'")
+	cmd.Flags().StringVarP(&opts.Solves, "solves", "", "", "Only challenges with this many solves, as 'min-max', 'min-' or '-max'")
+	cmd.Flags().StringSliceVarP(&opts.Tags, "tag", "", nil, "Only challenges with one of these tags (repeatable)")
+}
+
+// addConcurrencyFlag adds --concurrency to a command processing challenges.
+func addConcurrencyFlag(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&opts.Concurrency, "concurrency", "", 4, "Number of challenges processed at the same time")
+}
+
+// addReportFlag adds --format to a command printing a run report, its
+// --output is the directory of the challenges. --report-json is kept as an
+// alias of --format json.
+func addReportFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, formatFlagName, "", "table", fmt.Sprintf("Format of the plan and the report (%s)", strings.Join(outputFormats, "|")))
+	cmd.Flags().BoolVarP(&opts.ReportJSON, "report-json", "", false, "Print the final report as JSON")
+	CheckErr(cmd.Flags().MarkDeprecated("report-json", "use --format json instead"))
+}
+
+// challengeLayout returns the layout of the output directory, using --path-template
+// and the category-aliases map of the config file. Folders assigned by earlier
+// runs in the output directory are kept.
+func challengeLayout(cmd *cobra.Command) *ctfd.Layout {
+	layout, err := ctfd.OpenLayout(opts.Output, opts.PathTemplate, viper.GetStringMapString("category-aliases"))
+	if err != nil {
+		ShowHelp(cmd, err.Error())
+	}
+
+	return layout
+}
+
+// challengeFilter returns the filter set by the flags of addFilterFlags.
+func challengeFilter(cmd *cobra.Command, layout *ctfd.Layout) *ctfd.Filter {
+	filter := &ctfd.Filter{
+		Categories:   opts.Categories,
+		IDs:          opts.IDs,
+		Tags:         opts.Tags,
+		UnsolvedOnly: opts.UnsolvedOnly,
+		Layout:       layout,
+	}
+
+	var err error
+	if opts.NameRegex != "" {
+		filter.Name, err = regexp.Compile("(?i)" + opts.NameRegex)
+		if err != nil {
+			ShowHelp(cmd, fmt.Sprintf("Invalid --name regex: %v", err))
+		}
+	}
+
+	if filter.Value, err = ctfd.ParseRange(opts.Values); err != nil {
+		ShowHelp(cmd, fmt.Sprintf("Invalid --value: %v", err))
+	}
+
+	if filter.Solves, err = ctfd.ParseRange(opts.Solves); err != nil {
+		ShowHelp(cmd, fmt.Sprintf("Invalid --solves: %v", err))
+	}
+
+	return filter
+}
+
+// planChallenges decides what to do with every challenge. Challenges that
+// pass the filter get the action returned by decide for their folder.
+func planChallenges(challenges []ctfd.ChallengesData, layout *ctfd.Layout, filter *ctfd.Filter, decide func(challengePath string) (string, string)) []plannedChallenge {
+	var plan []plannedChallenge
+
+	// paths are computed from every challenge, so filters don't change them
+	paths := layout.Paths(challenges)
+
+	for _, challenge := range SortChallenges(challenges) {
+		planned := plannedChallenge{
+			Challenge: challenge,
+			Name:      lib.CleanSlug(challenge.Name, false),
+			Category:  layout.Category(challenge),
+		}
+
+		challengePath, ok := paths[challenge.ID]
+		if !ok {
+			planned.Action, planned.Reason = actionSkip, "invalid name or path"
+			plan = append(plan, planned)
+			continue
+		}
+
+		planned.Path = path.Join(opts.Output, challengePath)
+
+		if ok, reason := filter.Match(challenge); !ok {
+			planned.Action, planned.Reason = actionSkip, reason
+		} else {
+			planned.Action, planned.Reason = decide(planned.Path)
+		}
+
+		plan = append(plan, planned)
+	}
+
+	return plan
+}
+
+// pendingChallenges returns the challenges of the plan that are not skipped,
+// the skipped ones are added to the report.
+func pendingChallenges(plan []plannedChallenge, report *runReport) []plannedChallenge {
+	var pending []plannedChallenge
+	for _, planned := range plan {
+		if planned.Action == actionSkip {
+			log.Debugf("Skipping challenge %d : %s", planned.Challenge.ID, planned.Reason)
+			report.add(planned, statusSkipped, planned.Reason)
+			continue
+		}
+		pending = append(pending, planned)
+	}
+
+	return pending
+}
+
+// forEachChallenge calls process for every planned challenge, with at most
+// workers challenges processed at the same time and within --rate-limit.
+func forEachChallenge(plan []plannedChallenge, workers int, process func(plannedChallenge)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	rl := GetRateLimit()
+	jobs := make(chan plannedChallenge)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for planned := range jobs {
+				process(planned)
+			}
+		}()
+	}
+
+	for _, planned := range plan {
+		if options.RateLimit > 0 {
+			rl.Take()
+		}
+		jobs <- planned
+	}
+
+	close(jobs)
+	wg.Wait()
+}
+
+// completedStatus is the report status of a challenge processed without error,
+// created is the status of new challenges.
+func completedStatus(planned plannedChallenge, created string) string {
+	if planned.Action == actionUpdate {
+		return "updated"
+	}
+	return created
+}
+
+// removePartial removes the folder of a challenge that failed before it was
+// complete. Folders of existing challenges are kept, they can hold a writeup.
+func removePartial(planned plannedChallenge) {
+	if planned.Action != actionCreate || planned.Path == "" {
+		return
+	}
+
+	CheckWarn(os.RemoveAll(planned.Path))
+}
+
+// planRow is a planned challenge as printed by --dry-run.
+type planRow struct {
+	Action    string `json:"action"`
+	ID        int64  `json:"id"`
+	Challenge string `json:"challenge"`
+	Category  string `json:"category"`
+	Path      string `json:"path"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// planList is the output of --dry-run.
+type planList []planRow
+
+func newPlanList(plan []plannedChallenge) planList {
+	rows := planList{}
+	for _, planned := range plan {
+		rows = append(rows, planRow{
+			Action:    planned.Action,
+			ID:        planned.Challenge.ID,
+			Challenge: planned.Challenge.Name,
+			Category:  planned.Category,
+			Path:      planned.Path,
+			Reason:    planned.Reason,
+		})
+	}
+	return rows
+}
+
+func (l planList) Columns() []string {
+	return []string{"action", "id", "challenge", "category", "path", "reason"}
+}
+
+func (l planList) Rows() [][]string {
+	var rows [][]string
+	for _, row := range l {
+		rows = append(rows, []string{row.Action, strconv.FormatInt(row.ID, 10), row.Challenge, row.Category, row.Path, row.Reason})
+	}
+	return rows
+}
+
+// printPlan prints the planned actions for --dry-run in the --format format.
+func printPlan(plan []plannedChallenge) {
+	printResult(newPlanList(plan))
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/ritchies/ctftool/pkg/scraper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var registerOpts struct {
+	Email            string
+	RegistrationCode string
+	Fields           []string
+	CSV              string
+	Team             string
+	TeamPassword     string
+	JoinOnly         bool
+}
+
+// ctfdRegisterCmd represents the register command
+var ctfdRegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Register accounts and set up the team",
+	Long: `Register one or more accounts on a CTFd instance.
+
+With --team, the first account creates the team and every other account joins
+it with --team-password. Use --join to have every account join an existing team.
+Teammates can be read from a CSV file with name, email and password columns,
+every other column is used as a custom registration field.`,
+	Example: `  ctftool ctfd register --url https://demo.ctfd.io --username alice --email alice@example.com --field Discord=alice#1337
+  ctftool ctfd register --url https://demo.ctfd.io --csv teammates.csv --team pwners --team-password s3cret`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ctfd.NewClient()
+		ctfdOptions()
+
+		client.BaseURL = getBaseURL(cmd)
+
+		registrations := registrationsFromFlags(cmd)
+
+		if registerOpts.Team != "" && registerOpts.TeamPassword == "" {
+			ShowHelp(cmd, "A team password is required to create or join a team")
+		}
+
+		if !opts.SkipCTFDCheck {
+			CheckErr(ctfd.Check())
+		}
+
+		team := ctfd.TeamCredentials{Name: registerOpts.Team, Password: registerOpts.TeamPassword}
+
+		if failed := setupAccounts(client, registrations, team, registerOpts.JoinOnly); failed > 0 {
+			CheckErr(fmt.Errorf("%d of %d accounts could not be set up", failed, len(registrations)))
+		}
+	},
+}
+
+// setupAccounts registers every account and creates or joins the team, it
+// returns the number of accounts that could not be set up. The first account
+// registered successfully creates the team, unless joinOnly is set, and the
+// others join it. Failures are logged and don't stop the other accounts.
+func setupAccounts(client *scraper.Client, registrations []ctfd.Registration, team ctfd.TeamCredentials, joinOnly bool) int {
+	var failed int
+	teamCreated := joinOnly
+
+	for _, registration := range registrations {
+		// every account needs its own session
+		client.ResetSession()
+
+		if err := ctfd.Register(registration); err != nil {
+			log.WithField("name", registration.Name).Error(err)
+			failed++
+			continue
+		}
+
+		log.WithField("email", registration.Email).Infof("Registered %q", registration.Name)
+
+		if team.Name == "" {
+			continue
+		}
+
+		if !teamCreated {
+			if err := ctfd.CreateTeam(team); err != nil {
+				log.WithField("name", registration.Name).Error(err)
+				failed++
+				continue
+			}
+
+			teamCreated = true
+			log.WithField("name", registration.Name).Infof("Created team %q", team.Name)
+			continue
+		}
+
+		if err := ctfd.JoinTeam(team); err != nil {
+			log.WithField("name", registration.Name).Error(err)
+			failed++
+			continue
+		}
+
+		log.WithField("name", registration.Name).Infof("Joined team %q", team.Name)
+	}
+
+	return failed
+}
+
+// registrationsFromFlags returns the accounts to register, either from the
+// CSV file or from the username, email and password flags.
+func registrationsFromFlags(cmd *cobra.Command) []ctfd.Registration {
+	if registerOpts.CSV != "" {
+		return readTeammates(registerOpts.CSV)
+	}
+
+	if opts.Username == "" || registerOpts.Email == "" {
+		ShowHelp(cmd, "A username and an email, or a CSV file of teammates, are required")
+	}
+
+	if opts.Password == "" {
+		fmt.Fprint(os.Stderr, "Enter your password: ")
+		var password string
+		fmt.Scanln(&password)
+		opts.Password = strings.TrimSpace(password)
+	}
+
+	fields := make(map[string]string)
+	for _, field := range registerOpts.Fields {
+		label, value, found := strings.Cut(field, "=")
+		if !found {
+			ShowHelp(cmd, fmt.Sprintf("Invalid field %q, expected 'Label=value'", field))
+		}
+		fields[strings.TrimSpace(label)] = strings.TrimSpace(value)
+	}
+
+	return []ctfd.Registration{{
+		Name:             opts.Username,
+		Email:            registerOpts.Email,
+		Password:         opts.Password,
+		RegistrationCode: registerOpts.RegistrationCode,
+		Fields:           fields,
+	}}
+}
+
+// readTeammates reads the registrations from a CSV file of teammates.
+func readTeammates(file string) []ctfd.Registration {
+	f, err := os.Open(file)
+	CheckErr(err)
+	defer f.Close()
+
+	registrations, err := ctfd.ReadTeammates(f)
+	CheckErr(err)
+
+	return registrations
+}
+
+func init() {
+	ctfdCmd.AddCommand(ctfdRegisterCmd)
+
+	ctfdRegisterCmd.Flags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	ctfdRegisterCmd.Flags().StringVarP(&opts.Username, "username", "u", "", "Username of the new account")
+	ctfdRegisterCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password of the new account")
+	ctfdRegisterCmd.Flags().StringVarP(&registerOpts.Email, "email", "e", "", "Email of the new account")
+	ctfdRegisterCmd.Flags().StringVarP(&registerOpts.RegistrationCode, "registration-code", "", "", "Registration code, if the instance requires one")
+	ctfdRegisterCmd.Flags().StringArrayVarP(&registerOpts.Fields, "field", "", nil, "Custom registration field as 'Label=value' (repeatable)")
+	ctfdRegisterCmd.Flags().StringVarP(&registerOpts.CSV, "csv", "", "", "CSV file of teammates (name,email,password[,fields...])")
+	ctfdRegisterCmd.Flags().StringVarP(&registerOpts.Team, "team", "", "", "Name of the team to create or join")
+	ctfdRegisterCmd.Flags().StringVarP(&registerOpts.TeamPassword, "team-password", "", "", "Password of the team")
+	ctfdRegisterCmd.Flags().BoolVarP(&registerOpts.JoinOnly, "join", "", false, "Join an existing team instead of creating it")
+	ctfdRegisterCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+
+	// viper
+	err := viper.BindPFlag("url", ctfdRegisterCmd.Flags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", ctfdRegisterCmd.Flags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", ctfdRegisterCmd.Flags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("skip-check", ctfdRegisterCmd.Flags().Lookup("skip-check"))
+	CheckErr(err)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+const testRegisterPage = `<form method="post"><input name="name"><input name="email"><input name="password" type="password"><input name="nonce" type="hidden" value="abc"></form>`
+
+func TestSetupAccounts(t *testing.T) {
+	tests := []struct {
+		description string
+		names       []string
+		joinOnly    bool
+		failCreate  bool
+		wantCreated []string
+		wantJoined  []string
+		wantFailed  int
+	}{
+		{
+			description: "first account creates the team",
+			names:       []string{"alice", "bob", "carol"},
+			wantCreated: []string{"alice"},
+			wantJoined:  []string{"bob", "carol"},
+		},
+		{
+			description: "first registered account creates the team",
+			names:       []string{"taken", "bob", "carol"},
+			wantCreated: []string{"bob"},
+			wantJoined:  []string{"carol"},
+			wantFailed:  1,
+		},
+		{
+			description: "every account joins",
+			names:       []string{"alice", "bob"},
+			joinOnly:    true,
+			wantJoined:  []string{"alice", "bob"},
+		},
+		{
+			description: "failed team creation doesn't stop the others",
+			names:       []string{"alice", "bob"},
+			failCreate:  true,
+			wantFailed:  2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var mu sync.Mutex
+			var created, joined []string
+
+			// the session cookie tells which account is logged in
+			account := func(r *http.Request) string {
+				cookie, err := r.Cookie("session")
+				if err != nil {
+					return ""
+				}
+				return cookie.Value
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					if r.FormValue("name") == "taken" {
+						fmt.Fprint(w, `<div class="alert alert-danger alert-dismissible text-center" role="alert"><span>That user name is already taken</span></div>`)
+						return
+					}
+					http.SetCookie(w, &http.Cookie{Name: "session", Value: r.FormValue("name"), Path: "/"})
+					fmt.Fprint(w, `<html></html>`)
+					return
+				}
+				fmt.Fprint(w, testRegisterPage)
+			})
+
+			teamHandler := func(accounts *[]string, fail bool) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					if r.Method == http.MethodPost {
+						if fail {
+							fmt.Fprint(w, `<div class="alert alert-danger alert-dismissible text-center" role="alert"><span>That team name is already taken</span></div>`)
+							return
+						}
+						mu.Lock()
+						*accounts = append(*accounts, account(r))
+						mu.Unlock()
+						fmt.Fprint(w, `<html></html>`)
+						return
+					}
+					fmt.Fprint(w, `<form method="post"><input name="name"><input name="password" type="password"></form>`)
+				}
+			}
+			mux.HandleFunc("/teams/new", teamHandler(&created, test.failCreate))
+			mux.HandleFunc("/teams/join", teamHandler(&joined, false))
+
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := ctfd.NewClient()
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+
+			var registrations []ctfd.Registration
+			for _, name := range test.names {
+				registrations = append(registrations, ctfd.Registration{Name: name, Email: name + "@example.com", Password: "hunter2"})
+			}
+
+			failed := setupAccounts(client, registrations, ctfd.TeamCredentials{Name: "pwners", Password: "s3cret"}, test.joinOnly)
+
+			if failed != test.wantFailed {
+				t.Errorf("got %d failed accounts, want %d", failed, test.wantFailed)
+			}
+			if !cmp.Equal(created, test.wantCreated) {
+				t.Errorf("got team created by %v, want %v", created, test.wantCreated)
+			}
+			if !cmp.Equal(joined, test.wantJoined) {
+				t.Errorf("got team joined by %v, want %v", joined, test.wantJoined)
+			}
+		})
+	}
+}
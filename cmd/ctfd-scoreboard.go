@@ -0,0 +1,458 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/ritchies/ctftool/pkg/scraper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var graphOpts struct {
+	Count  int
+	Since  string
+	Until  string
+	SVG    string
+	Width  int
+	Height int
+}
+
+var exportDir string // directory the snapshots of scoreboard export are written to
+
+// ctfdScoreboardCmd represents the scoreboard command
+var ctfdScoreboardCmd = &cobra.Command{
+	Use:   "scoreboard",
+	Short: "Chart and export the scoreboard",
+	Long:  `Chart and export the scoreboard of a CTFd instance.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Help()
+		CheckErr(err)
+	},
+}
+
+// ctfdScoreboardGraphCmd represents the scoreboard graph command
+var ctfdScoreboardGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Chart the score of the top teams over time",
+	Long: `Chart the cumulative score of the top teams over time in the terminal,
+with your own team highlighted when credentials are given.
+
+--since and --until limit the chart to a time window, as a duration before
+now (6h) or a RFC 3339 time. The other formats of --output print the score
+of every team after each solve, --svg also draws the chart to an SVG file.`,
+	Example: `  ctftool ctfd scoreboard graph --url https://demo.ctfd.io --token abcdef12356
+  ctftool ctfd scoreboard graph --count 5 --since 12h
+  ctftool ctfd scoreboard graph --output csv > scores.csv
+  ctftool ctfd scoreboard graph --svg scores.svg`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ctfd.NewClient()
+		ctfdOptions()
+
+		client.BaseURL = getBaseURL(cmd)
+		client.Creds = &scraper.Credentials{
+			Username: opts.Username,
+			Password: opts.Password,
+			Token:    opts.Token,
+		}
+
+		now := time.Now()
+		since, err := parseTimeFlag(graphOpts.Since, now)
+		if err != nil {
+			ShowHelp(cmd, fmt.Sprintf("Invalid --since: %v", err))
+		}
+		until, err := parseTimeFlag(graphOpts.Until, now)
+		if err != nil {
+			ShowHelp(cmd, fmt.Sprintf("Invalid --until: %v", err))
+		}
+
+		if !opts.SkipCTFDCheck {
+			CheckErr(ctfd.Check())
+		}
+
+		if opts.Username != "" && opts.Password != "" && opts.Token == "" {
+			err := ctfd.Authenticate()
+			CheckErr(err)
+			log.Infof("Authenticated as %q", opts.Username)
+		}
+
+		standings, err := ctfd.TopStandings(graphOpts.Count)
+		CheckErr(err)
+
+		var mine *ctfd.Team
+		if opts.Token != "" || opts.Password != "" || hasCookies() {
+			mine, err = ctfd.MyTeam()
+			CheckWarn(err)
+		}
+
+		graph := newScoreGraph(standings, mine, since, until)
+		graph.Width, graph.Height = chartSize(graphOpts.Width, graphOpts.Height)
+
+		if graphOpts.SVG != "" {
+			err := os.WriteFile(graphOpts.SVG, []byte(svgChart(graph.series(), graph.From, graph.To, 1000, 500)), 0644)
+			CheckErr(err)
+			log.WithField("file", graphOpts.SVG).Info("Wrote the score chart")
+		}
+
+		printResult(graph)
+	},
+}
+
+// ctfdScoreboardExportCmd represents the scoreboard export command
+var ctfdScoreboardExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the whole scoreboard",
+	Long: `Export the whole scoreboard with the time of the snapshot, the bracket of
+every account and, in team mode, the members of the teams with their own
+score. Every page of the scoreboard is requested.
+
+The CSV output has a line for every member, or for every account without
+members. With --dir the snapshot is written to a file of the directory
+instead, named after its time, and with --watch a snapshot is taken every
+--watch-interval to keep the history of the scoreboard. The files are
+written in the --output format, JSON unless it is csv or yaml.`,
+	Example: `  ctftool ctfd scoreboard export --url https://demo.ctfd.io --output csv > standings.csv
+  ctftool ctfd scoreboard export --output json | jq '.standings[] | select(.bracket_name == "Students")'
+  ctftool ctfd scoreboard export --dir snapshots --watch --watch-interval 15m`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ctfd.NewClient()
+		ctfdOptions()
+
+		client.BaseURL = getBaseURL(cmd)
+		client.Creds = &scraper.Credentials{
+			Username: opts.Username,
+			Password: opts.Password,
+			Token:    opts.Token,
+		}
+
+		if !opts.SkipCTFDCheck {
+			CheckErr(ctfd.Check())
+		}
+
+		if opts.Username != "" && opts.Password != "" && opts.Token == "" {
+			err := ctfd.Authenticate()
+			CheckErr(err)
+			log.Infof("Authenticated as %q", opts.Username)
+		}
+
+		if exportDir != "" {
+			CheckErr(os.MkdirAll(exportDir, os.ModePerm))
+		}
+
+		snapshot := func() error {
+			scoreboard, err := ctfd.FullScoreboard()
+			if err != nil {
+				return err
+			}
+
+			if exportDir == "" {
+				printResult(scoreboardSnapshot{scoreboard})
+				return nil
+			}
+
+			filename, err := writeSnapshot(exportDir, outputFormat, scoreboardSnapshot{scoreboard})
+			if err != nil {
+				return err
+			}
+			log.WithField("file", filename).Infof("Saved the scoreboard, %d accounts", len(scoreboard.Standings))
+			return nil
+		}
+
+		CheckErr(snapshot())
+
+		if opts.Watch {
+			watch("the scoreboard", func() {
+				// a temporary error must not stop watching
+				if err := snapshot(); err != nil {
+					log.Warnf("Exporting the scoreboard failed: %v", err)
+				}
+			})
+		}
+	},
+}
+
+// writeSnapshot writes the snapshot to a file of dir named after its time, in
+// the format if it is csv or yaml and JSON otherwise.
+func writeSnapshot(dir string, format string, snapshot scoreboardSnapshot) (string, error) {
+	if format != "csv" && format != "yaml" {
+		format = "json"
+	}
+
+	filename := filepath.Join(dir, fmt.Sprintf("scoreboard-%s.%s", snapshot.Time.Format("20060102T150405Z"), format))
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+
+	if err := writeResult(f, format, "", snapshot); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	return filename, f.Close()
+}
+
+// scoreboardSnapshot is the output of scoreboard export.
+type scoreboardSnapshot struct {
+	*ctfd.Scoreboard
+}
+
+func (s scoreboardSnapshot) Columns() []string {
+	return []string{"time", "pos", "account_id", "account_type", "name", "score", "bracket", "member_id", "member_name", "member_score"}
+}
+
+func (s scoreboardSnapshot) Rows() [][]string {
+	time := s.Time.Format(time.RFC3339)
+
+	var rows [][]string
+	for _, entry := range s.Standings {
+		account := []string{time, strconv.Itoa(entry.Position), strconv.Itoa(entry.AccountID), entry.AccountType, entry.Name, strconv.Itoa(entry.Score), entry.BracketName}
+
+		if len(entry.Members) == 0 {
+			rows = append(rows, append(account, "", "", ""))
+			continue
+		}
+
+		for _, member := range entry.Members {
+			row := append(append([]string{}, account...), strconv.Itoa(member.ID), member.Name, strconv.Itoa(member.Score))
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Render prints the standings with the members of the teams and their score.
+func (s scoreboardSnapshot) Render(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(tw, "POS\tNAME\tSCORE\tBRACKET\tMEMBERS")
+
+	for _, entry := range s.Standings {
+		var members []string
+		for _, member := range entry.Members {
+			members = append(members, fmt.Sprintf("%s (%d)", member.Name, member.Score))
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%s\n", entry.Position, entry.Name, entry.Score, entry.BracketName, strings.Join(members, ", "))
+	}
+
+	return tw.Flush()
+}
+
+// parseTimeFlag parses a time flag, a duration before now or a RFC 3339
+// time. An empty value is the zero time.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a duration nor a RFC 3339 time", value)
+	}
+
+	return t, nil
+}
+
+// chartSize returns the size of the chart in the terminal, the width of the
+// terminal unless a width is given.
+func chartSize(width, height int) (int, int) {
+	if width <= 0 {
+		width = 80
+		if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+			width = w
+		}
+	}
+
+	if height <= 0 {
+		height = 20
+	}
+
+	return width, height
+}
+
+// teamTimeline is the score of a team over time, as printed by scoreboard graph.
+type teamTimeline struct {
+	Rank   int               `json:"rank"` // 0 for our team outside of the top
+	ID     int               `json:"id"`
+	Name   string            `json:"name"`
+	Mine   bool              `json:"mine"`
+	Points []ctfd.ScorePoint `json:"points"`
+}
+
+// scoreGraph is the output of scoreboard graph.
+type scoreGraph struct {
+	Teams         []teamTimeline
+	From, To      time.Time
+	Width, Height int
+}
+
+// newScoreGraph returns the timelines of the teams and of our team between
+// since and until. A zero since or until is the first or last solve.
+func newScoreGraph(standings []ctfd.Standing, mine *ctfd.Team, since, until time.Time) *scoreGraph {
+	graph := &scoreGraph{Teams: []teamTimeline{}}
+
+	var first, last time.Time
+	add := func(timeline teamTimeline, team ctfd.Team) {
+		timeline.Points = team.Timeline()
+		if n := len(timeline.Points); n > 0 {
+			if first.IsZero() || timeline.Points[0].Date.Before(first) {
+				first = timeline.Points[0].Date
+			}
+			if timeline.Points[n-1].Date.After(last) {
+				last = timeline.Points[n-1].Date
+			}
+		}
+		graph.Teams = append(graph.Teams, timeline)
+	}
+
+	found := false
+	for _, standing := range standings {
+		isMine := mine != nil && mine.ID == standing.ID
+		found = found || isMine
+		add(teamTimeline{Rank: standing.Rank, ID: standing.ID, Name: standing.Name, Mine: isMine}, standing.Team)
+	}
+
+	if mine != nil && !found {
+		add(teamTimeline{ID: mine.ID, Name: mine.Name, Mine: true}, *mine)
+	}
+
+	graph.From, graph.To = first, last
+	if !since.IsZero() {
+		graph.From = since
+	}
+	if !until.IsZero() {
+		graph.To = until
+	}
+
+	for i := range graph.Teams {
+		graph.Teams[i].Points = windowTimeline(graph.Teams[i].Points, graph.From, graph.To)
+		if graph.Teams[i].Points == nil {
+			graph.Teams[i].Points = []ctfd.ScorePoint{}
+		}
+	}
+
+	return graph
+}
+
+// series returns the lines of the chart, our team in bold.
+func (g *scoreGraph) series() []chartSeries {
+	var series []chartSeries
+	for _, team := range g.Teams {
+		name := fmt.Sprintf("%d. %s", team.Rank, team.Name)
+		if team.Rank == 0 {
+			name = team.Name
+		}
+		if team.Mine {
+			name += " (us)"
+		}
+		series = append(series, chartSeries{Name: name, Points: team.Points, Bold: team.Mine})
+	}
+	return series
+}
+
+func (g *scoreGraph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.Teams)
+}
+
+func (g *scoreGraph) Columns() []string {
+	return []string{"rank", "id", "team", "date", "score"}
+}
+
+func (g *scoreGraph) Rows() [][]string {
+	var rows [][]string
+	for _, team := range g.Teams {
+		for _, point := range team.Points {
+			rows = append(rows, []string{
+				strconv.Itoa(team.Rank),
+				strconv.Itoa(team.ID),
+				team.Name,
+				point.Date.UTC().Format(time.RFC3339),
+				strconv.Itoa(point.Score),
+			})
+		}
+	}
+	return rows
+}
+
+// Render draws the chart.
+func (g *scoreGraph) Render(w io.Writer) error {
+	if len(g.Teams) == 0 {
+		log.Info("The scoreboard is empty")
+		return nil
+	}
+
+	_, err := io.WriteString(w, brailleChart(g.series(), g.From, g.To, g.Width, g.Height))
+	return err
+}
+
+func init() {
+	ctfdCmd.AddCommand(ctfdScoreboardCmd)
+	ctfdScoreboardCmd.AddCommand(ctfdScoreboardGraphCmd)
+	ctfdScoreboardCmd.AddCommand(ctfdScoreboardExportCmd)
+
+	ctfdScoreboardGraphCmd.Flags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	ctfdScoreboardGraphCmd.Flags().StringVarP(&opts.Username, "username", "u", "", "Username for CTFd authentication")
+	ctfdScoreboardGraphCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	ctfdScoreboardGraphCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
+	ctfdScoreboardGraphCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+	ctfdScoreboardGraphCmd.Flags().IntVarP(&graphOpts.Count, "count", "n", 10, "Number of top teams to chart")
+	ctfdScoreboardGraphCmd.Flags().StringVarP(&graphOpts.Since, "since", "", "", "Start of the chart, a duration before now (6h) or a RFC 3339 time")
+	ctfdScoreboardGraphCmd.Flags().StringVarP(&graphOpts.Until, "until", "", "", "End of the chart, a duration before now (1h) or a RFC 3339 time")
+	ctfdScoreboardGraphCmd.Flags().StringVarP(&graphOpts.SVG, "svg", "", "", "Also draw the chart to an SVG file")
+	ctfdScoreboardGraphCmd.Flags().IntVarP(&graphOpts.Width, "width", "", 0, "Width of the chart in characters (defaults to the terminal width)")
+	ctfdScoreboardGraphCmd.Flags().IntVarP(&graphOpts.Height, "height", "", 20, "Height of the chart in lines")
+
+	// viper
+	err := viper.BindPFlag("url", ctfdScoreboardGraphCmd.Flags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", ctfdScoreboardGraphCmd.Flags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", ctfdScoreboardGraphCmd.Flags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("token", ctfdScoreboardGraphCmd.Flags().Lookup("token"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("skip-check", ctfdScoreboardGraphCmd.Flags().Lookup("skip-check"))
+	CheckErr(err)
+
+	ctfdScoreboardExportCmd.Flags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	ctfdScoreboardExportCmd.Flags().StringVarP(&opts.Username, "username", "u", "", "Username for CTFd authentication")
+	ctfdScoreboardExportCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	ctfdScoreboardExportCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
+	ctfdScoreboardExportCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+	ctfdScoreboardExportCmd.Flags().StringVarP(&exportDir, "dir", "", "", "Write the snapshot to a file of this directory")
+	ctfdScoreboardExportCmd.Flags().BoolVarP(&opts.Watch, "watch", "w", false, "Take a snapshot every --watch-interval")
+	ctfdScoreboardExportCmd.Flags().DurationVarP(&opts.WatchInterval, "watch-interval", "", 15*time.Minute, "Interval between snapshots")
+
+	// viper
+	err = viper.BindPFlag("url", ctfdScoreboardExportCmd.Flags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", ctfdScoreboardExportCmd.Flags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", ctfdScoreboardExportCmd.Flags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("token", ctfdScoreboardExportCmd.Flags().Lookup("token"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("skip-check", ctfdScoreboardExportCmd.Flags().Lookup("skip-check"))
+	CheckErr(err)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+func at(hour int) time.Time {
+	return time.Date(2023, 1, 1, hour, 0, 0, 0, time.UTC)
+}
+
+func TestParseTimeFlag(t *testing.T) {
+	now := at(12)
+
+	tests := []struct {
+		description string
+		value       string
+		want        time.Time
+		wantErr     bool
+	}{
+		{description: "empty", value: "", want: time.Time{}},
+		{description: "duration before now", value: "3h", want: at(9)},
+		{description: "RFC 3339", value: "2023-01-01T10:00:00Z", want: at(10)},
+		{description: "invalid", value: "yesterday", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseTimeFlag(test.value, now)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: got error %v, want error %v", test.description, err, test.wantErr)
+			continue
+		}
+
+		if !got.Equal(test.want) {
+			t.Errorf("%s: got %v, want %v", test.description, got, test.want)
+		}
+	}
+}
+
+func TestWindowTimeline(t *testing.T) {
+	timeline := []ctfd.ScorePoint{{Date: at(8), Score: 100}, {Date: at(9), Score: 300}, {Date: at(11), Score: 400}, {Date: at(13), Score: 500}}
+
+	got := windowTimeline(timeline, at(10), at(12))
+	want := []ctfd.ScorePoint{{Date: at(10), Score: 300}, {Date: at(11), Score: 400}}
+
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestNewScoreGraph(t *testing.T) {
+	standings := []ctfd.Standing{
+		{Rank: 1, Team: ctfd.Team{ID: 7, Name: "first", Solves: []ctfd.Solves{{Value: 300, Date: at(9)}, {Value: 100, Date: at(11)}}}},
+		{Rank: 2, Team: ctfd.Team{ID: 3, Name: "second", Solves: []ctfd.Solves{{Value: 200, Date: at(10)}}}},
+	}
+	mine := &ctfd.Team{ID: 42, Name: "us", Solves: []ctfd.Solves{{Value: 50, Date: at(12)}}}
+
+	graph := newScoreGraph(standings, mine, time.Time{}, time.Time{})
+
+	if !graph.From.Equal(at(9)) || !graph.To.Equal(at(12)) {
+		t.Errorf("got window %v to %v, want the first and last solve", graph.From, graph.To)
+	}
+
+	var names []string
+	for _, series := range graph.series() {
+		names = append(names, series.Name)
+	}
+	if want := []string{"1. first", "2. second", "us (us)"}; !cmp.Equal(names, want) {
+		t.Errorf("got series %v, want %v", names, want)
+	}
+
+	rows := graph.Rows()
+	if want := []string{"1", "7", "first", "2023-01-01T11:00:00Z", "400"}; !cmp.Equal(rows[1], want) {
+		t.Errorf("got row %v, want %v", rows[1], want)
+	}
+
+	// our team in the top isn't added twice
+	graph = newScoreGraph(standings, &ctfd.Team{ID: 3, Name: "second"}, at(10), time.Time{})
+	if len(graph.Teams) != 2 || !graph.Teams[1].Mine {
+		t.Errorf("got teams %+v, want the second one marked as ours", graph.Teams)
+	}
+	if got := graph.Teams[0].Points[0]; !got.Date.Equal(at(10)) || got.Score != 300 {
+		t.Errorf("got first point %+v, want the score before --since", got)
+	}
+}
+
+func TestBrailleChart(t *testing.T) {
+	series := []chartSeries{
+		{Name: "1. first", Points: []ctfd.ScorePoint{{Date: at(9), Score: 100}, {Date: at(11), Score: 400}}},
+		{Name: "us (us)", Points: []ctfd.ScorePoint{{Date: at(10), Score: 200}}, Bold: true},
+	}
+
+	chart := brailleChart(series, at(9), at(12), 40, 5)
+	lines := strings.Split(strings.TrimRight(chart, "\n"), "\n")
+
+	// 5 lines of dots, the time axis and its labels, a blank line and the legend
+	if len(lines) != 10 {
+		t.Fatalf("got %d lines, want 10:\n%s", len(lines), chart)
+	}
+
+	if !strings.HasPrefix(lines[0], "400 ┤") || !strings.HasPrefix(lines[4], "  0 ┤") {
+		t.Errorf("unexpected score axis:\n%s", chart)
+	}
+
+	if !strings.ContainsAny(lines[0], "⠁⠉⣀⣿⡇⢸") {
+		t.Errorf("the highest score is not drawn on the first line:\n%s", chart)
+	}
+
+	for _, want := range []string{"1. first 400", "us (us) 200"} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("legend misses %q:\n%s", want, chart)
+		}
+	}
+}
+
+func TestSVGChart(t *testing.T) {
+	series := []chartSeries{{Name: "<script>", Points: []ctfd.ScorePoint{{Date: at(10), Score: 100}}}}
+
+	svg := svgChart(series, at(9), at(11), 1000, 500)
+
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Errorf("not an SVG image:\n%s", svg)
+	}
+
+	if strings.Contains(svg, "<script>") || !strings.Contains(svg, "&lt;script&gt;") {
+		t.Errorf("team name is not escaped:\n%s", svg)
+	}
+}
+
+func testSnapshot() scoreboardSnapshot {
+	return scoreboardSnapshot{&ctfd.Scoreboard{
+		Time: at(12),
+		Standings: []ctfd.ScoreboardEntry{
+			{Position: 1, AccountID: 7, AccountType: "team", Name: "first", Score: 500, BracketName: "Students", Members: []ctfd.ScoreboardMember{
+				{ID: 1, Name: "alice", Score: 300},
+				{ID: 2, Name: "bob", Score: 200},
+			}},
+			{Position: 2, AccountID: 3, AccountType: "team", Name: "second", Score: 100},
+		},
+	}}
+}
+
+func TestScoreboardSnapshot_Rows(t *testing.T) {
+	got := testSnapshot().Rows()
+	want := [][]string{
+		{"2023-01-01T12:00:00Z", "1", "7", "team", "first", "500", "Students", "1", "alice", "300"},
+		{"2023-01-01T12:00:00Z", "1", "7", "team", "first", "500", "Students", "2", "bob", "200"},
+		{"2023-01-01T12:00:00Z", "2", "3", "team", "second", "100", "", "", "", ""},
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWriteSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		description string
+		format      string
+		want        string
+	}{
+		{description: "csv", format: "csv", want: "scoreboard-20230101T120000Z.csv"},
+		{description: "table is written as JSON", format: "table", want: "scoreboard-20230101T120000Z.json"},
+	}
+
+	for _, test := range tests {
+		filename, err := writeSnapshot(dir, test.format, testSnapshot())
+		if err != nil {
+			t.Fatalf("%s: writeSnapshot() returned error: %v", test.description, err)
+		}
+
+		if want := filepath.Join(dir, test.want); filename != want {
+			t.Errorf("%s: got %q, want %q", test.description, filename, want)
+		}
+
+		data, err := os.ReadFile(filename)
+		if err != nil || !strings.Contains(string(data), "alice") {
+			t.Errorf("%s: got %q (%v), want the snapshot", test.description, data, err)
+		}
+	}
+}
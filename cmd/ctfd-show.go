@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// showWidth is the width the description of a challenge is wrapped at.
+const showWidth = 80
+
+var showRaw bool // print the challenge as JSON
+
+var (
+	showTitleStyle   = lipgloss.NewStyle().Bold(true)
+	showHeadingStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+	showDimStyle     = lipgloss.NewStyle().Faint(true)
+	showTagStyle     = lipgloss.NewStyle().Padding(0, 1).
+				Foreground(lipgloss.AdaptiveColor{Light: "255", Dark: "235"}).
+				Background(lipgloss.AdaptiveColor{Light: "63", Dark: "111"})
+)
+
+// ctfdShowCmd represents the show command
+var ctfdShowCmd = &cobra.Command{
+	Use:   "show <id|name>",
+	Short: "Show a challenge",
+	Long: `Show a challenge in the terminal, with its value, solves, tags, connection
+info, files and their size, description and hints. For the unsolved dynamic
+challenges whose decay is exposed, the value if you solve it now and the
+points lost if another team solves it first are shown too.
+
+The challenge is selected by ID or by name. Names are matched loosely, an
+exact name wins over a prefix, a prefix over a part of the name, and a part
+of the name over its letters in order.`,
+	Example: `  ctftool ctfd show 12 --url https://demo.ctfd.io --token abcdef12356
+  ctftool ctfd show baby rop
+  ctftool ctfd show cookies --output json | jq .description`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ctfd.NewClient()
+		ctfdOptions()
+
+		client.BaseURL = getBaseURL(cmd)
+		client.Creds = getCredentials(cmd)
+
+		if !opts.SkipCTFDCheck {
+			CheckErr(ctfd.Check())
+		}
+
+		if (opts.Username != "" || opts.Password != "") && opts.Token == "" {
+			err := ctfd.Authenticate()
+			CheckErr(err)
+			log.Infof("Authenticated as %q", opts.Username)
+		}
+
+		id, err := findChallengeID(strings.Join(args, " "))
+		CheckErr(err)
+
+		challenge, err := ctfd.Challenge(id)
+		CheckErr(err)
+
+		if showRaw {
+			outputFormat = "json"
+		}
+
+		printResult(newChallengeDetail(challenge))
+	},
+}
+
+// challengeDetail is the output of ctfd show, the projection is set for the
+// unsolved dynamic challenges whose decay is exposed.
+type challengeDetail struct {
+	*ctfd.ChallengeData
+	Projection *ctfd.Projection `json:"projection,omitempty"`
+}
+
+func newChallengeDetail(challenge *ctfd.ChallengeData) *challengeDetail {
+	return &challengeDetail{ChallengeData: challenge, Projection: projectChallenge(challenge)}
+}
+
+// projectChallenge returns the projection of the value of an unsolved dynamic
+// challenge, nil when there is nothing to lose by waiting.
+func projectChallenge(challenge *ctfd.ChallengeData) *ctfd.Projection {
+	scoring, ok := challenge.Scoring()
+	if !ok || challenge.SolvedByMe {
+		return nil
+	}
+
+	projection := scoring.Project(challenge.Solves)
+	return &projection
+}
+
+func (c *challengeDetail) Columns() []string {
+	return challengeList{}.Columns()
+}
+
+func (c *challengeDetail) Rows() [][]string {
+	row := challengeRow{
+		ID:       c.ID,
+		Category: c.Category,
+		Name:     c.Name,
+		Value:    c.Value,
+		Solves:   c.Solves,
+		Solved:   c.SolvedByMe,
+	}
+	if c.Projection != nil {
+		row.Expected, row.Loss = &c.Projection.Expected, &c.Projection.Loss
+	}
+	for _, tag := range c.Tags {
+		row.Tags = append(row.Tags, tag.Value)
+	}
+	return challengeList{row}.Rows()
+}
+
+// Render prints the challenge for the terminal, the size of its files is
+// only requested for it.
+func (c *challengeDetail) Render(w io.Writer) error {
+	_, err := io.WriteString(w, renderChallenge(c.ChallengeData, ctfd.ChallengeFiles(c.ChallengeData), showWidth))
+	return err
+}
+
+// findChallengeID returns the ID of the challenge selected by ID or by name.
+func findChallengeID(query string) (int64, error) {
+	if id, err := strconv.ParseInt(query, 10, 64); err == nil {
+		return id, nil
+	}
+
+	challenges, err := ctfd.ListChallenges()
+	if err != nil {
+		return 0, err
+	}
+
+	challenge, err := ctfd.FindChallenge(challenges, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return challenge.ID, nil
+}
+
+// renderChallenge renders a challenge and its files for the terminal, the
+// description is wrapped at width.
+func renderChallenge(challenge *ctfd.ChallengeData, files []ctfd.ChallengeFile, width int) string {
+	var b strings.Builder
+
+	solved := "❌ unsolved"
+	if challenge.SolvedByMe {
+		solved = "✅ solved"
+	}
+
+	fmt.Fprintf(&b, "%s %s\n", showTitleStyle.Render(challenge.Name), showDimStyle.Render(fmt.Sprintf("#%d %s", challenge.ID, challenge.Category)))
+	fmt.Fprintf(&b, "%d points · %d solves · %s\n", challenge.Value, challenge.Solves, solved)
+
+	if projection := projectChallenge(challenge); projection != nil {
+		scoring, _ := challenge.Scoring()
+		fmt.Fprintf(&b, "%s\n", showDimStyle.Render(fmt.Sprintf("%d points if solved now · -%d if another team solves it first · %d minimum",
+			projection.Expected, projection.Loss, int64(scoring.Minimum))))
+	}
+
+	if len(challenge.Tags) > 0 {
+		var tags []string
+		for _, tag := range challenge.Tags {
+			tags = append(tags, showTagStyle.Render(tag.Value))
+		}
+		fmt.Fprintf(&b, "%s\n", strings.Join(tags, " "))
+	}
+
+	if challenge.ConnectionInfo != "" {
+		fmt.Fprintf(&b, "\n%s\n%s\n", showHeadingStyle.Render("Connection"), challenge.ConnectionInfo)
+	}
+
+	if len(files) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", showHeadingStyle.Render("Files"))
+		for _, file := range files {
+			size := "unknown size"
+			if file.Size >= 0 {
+				size = humanizeBytes(file.Size)
+			}
+			fmt.Fprintf(&b, "  %s %s\n", file.Name, showDimStyle.Render(size))
+		}
+	}
+
+	if description := ctfd.DescriptionText(challenge.Description); description != "" {
+		fmt.Fprintf(&b, "\n%s\n%s\n", showHeadingStyle.Render("Description"), wordwrap.String(description, width))
+	}
+
+	if len(challenge.Hints) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", showHeadingStyle.Render("Hints"))
+		for _, hint := range challenge.Hints {
+			if hint.Locked() {
+				fmt.Fprintf(&b, "  - %s\n", showDimStyle.Render(fmt.Sprintf("locked, unlocking costs %d points", hint.Cost)))
+				continue
+			}
+			fmt.Fprintf(&b, "  - %s\n", hint.Content)
+		}
+	}
+
+	return b.String()
+}
+
+func init() {
+	ctfdCmd.AddCommand(ctfdShowCmd)
+
+	ctfdShowCmd.Flags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	ctfdShowCmd.Flags().StringVarP(&opts.Username, "username", "u", "", "Username for CTFd authentication")
+	ctfdShowCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	ctfdShowCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
+	ctfdShowCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+	ctfdShowCmd.Flags().BoolVarP(&showRaw, "raw", "", false, "Print the challenge as JSON")
+	CheckErr(ctfdShowCmd.Flags().MarkDeprecated("raw", "use --output json instead"))
+
+	// viper
+	err := viper.BindPFlag("url", ctfdShowCmd.Flags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", ctfdShowCmd.Flags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", ctfdShowCmd.Flags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("token", ctfdShowCmd.Flags().Lookup("token"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("skip-check", ctfdShowCmd.Flags().Lookup("skip-check"))
+	CheckErr(err)
+}
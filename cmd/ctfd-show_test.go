@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+func TestRenderChallenge(t *testing.T) {
+	challenge := &ctfd.ChallengeData{
+		ID:             12,
+		Name:           "Baby ROP",
+		Category:       "pwn",
+		Value:          300,
+		Solves:         4,
+		SolvedByMe:     true,
+		ConnectionInfo: "nc pwn.example.com 1337",
+		Description:    "<p>Smash the <code>stack</code> &amp; get a shell</p>",
+		Tags:           []ctfd.Tag{{Value: "easy"}},
+		Hints:          []ctfd.Hint{{Content: "ret2win"}, {ID: 2, Cost: 50}},
+	}
+	files := []ctfd.ChallengeFile{{Name: "rop", Size: 16000}, {Name: "libc.so.6", Size: -1}}
+
+	got := renderChallenge(challenge, files, showWidth)
+
+	for _, want := range []string{
+		"Baby ROP",
+		"#12 pwn",
+		"300 points · 4 solves · ✅ solved",
+		"easy",
+		"nc pwn.example.com 1337",
+		"rop 16.0 kB",
+		"libc.so.6 unknown size",
+		"Smash the `stack` & get a shell",
+		"- ret2win",
+		"locked, unlocking costs 50 points",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+
+	for _, line := range strings.Split(got, "\n") {
+		if len([]rune(line)) > showWidth {
+			t.Errorf("line is wider than %d: %q", showWidth, line)
+		}
+	}
+}
+
+func TestRenderChallenge_Dynamic(t *testing.T) {
+	challenge := &ctfd.ChallengeData{
+		Name:         "heap",
+		Value:        472,
+		Solves:       5,
+		DynamicValue: ctfd.DynamicValue{Initial: 500, Minimum: 100, Decay: 15},
+	}
+
+	if got, want := renderChallenge(challenge, nil, showWidth), "456 points if solved now · -20 if another team solves it first · 100 minimum"; !strings.Contains(got, want) {
+		t.Errorf("expected %q in:\n%s", want, got)
+	}
+
+	challenge.SolvedByMe = true
+	if got := renderChallenge(challenge, nil, showWidth); strings.Contains(got, "if solved now") {
+		t.Errorf("projection shown for a solved challenge:\n%s", got)
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var statsEasiest int // number of easiest unsolved challenges of ctfd stats
+
+// ctfdStatsCmd represents the stats command
+var ctfdStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize your progress on the board",
+	Long: `Summarize your progress on the board: per category the challenges solved
+and the points earned out of the points available, your share of all the
+points, your awards and the costs of the hints you unlocked. The easiest
+challenges you haven't solved, by solve count, and the challenges nobody
+has solved are listed below.
+
+The other formats of --output print the categories, except json which
+prints the whole summary.`,
+	Example: `  ctftool ctfd stats --url https://demo.ctfd.io --token abcdef12356
+  ctftool ctfd stats --count 10
+  ctftool ctfd stats --output json | jq '.unsolved[].name'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ctfd.NewClient()
+		ctfdOptions()
+
+		if statsEasiest < 0 {
+			ShowHelp(cmd, fmt.Sprintf("Invalid --count %d, expected 0 or more", statsEasiest))
+		}
+
+		client.BaseURL = getBaseURL(cmd)
+		client.Creds = getCredentials(cmd)
+
+		if !opts.SkipCTFDCheck {
+			CheckErr(ctfd.Check())
+		}
+
+		if (opts.Username != "" || opts.Password != "") && opts.Token == "" {
+			err := ctfd.Authenticate()
+			CheckErr(err)
+			log.Infof("Authenticated as %q", opts.Username)
+		}
+
+		challenges, err := ctfd.ListChallenges()
+		CheckErr(err)
+
+		account, err := ctfd.MyTeam()
+		CheckErr(err)
+
+		awards, err := ctfd.Awards(account)
+		if err != nil {
+			// awards are hidden on some instances, the score is then the solves only
+			log.Warnf("Failed to get the awards: %v", err)
+		}
+
+		printResult(statsReport{ctfd.NewStats(challenges, account, awards, statsEasiest)})
+	},
+}
+
+// statsReport is the output of ctfd stats, its rows are the categories.
+type statsReport struct {
+	*ctfd.Stats
+}
+
+func (r statsReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Stats)
+}
+
+func (r statsReport) Columns() []string {
+	return []string{"category", "total", "solved", "available", "earned"}
+}
+
+func (r statsReport) Rows() [][]string {
+	var rows [][]string
+	for _, category := range r.Categories {
+		rows = append(rows, []string{
+			category.Category,
+			strconv.Itoa(category.Total),
+			strconv.Itoa(category.Solved),
+			strconv.FormatInt(category.Available, 10),
+			strconv.FormatInt(category.Earned, 10),
+		})
+	}
+	return rows
+}
+
+// Render prints the categories with a total, the score and the lists of
+// challenges.
+func (r statsReport) Render(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(tw, "CATEGORY\tSOLVED\tPOINTS")
+	for _, category := range r.Categories {
+		fmt.Fprintf(tw, "%s\t%d/%d\t%d/%d\n", category.Category, category.Solved, category.Total, category.Earned, category.Available)
+	}
+	fmt.Fprintf(tw, "Total\t%d/%d\t%d/%d\n", r.Solved, r.Total, r.Earned, r.Available)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nShare of the points: %.1f%%\n", r.Share*100)
+	fmt.Fprintf(w, "Score: %d (%d from challenges, %d from awards, -%d for hints)\n", r.Score, r.Earned, r.Awards, r.HintCosts)
+
+	lists := []struct {
+		title      string
+		challenges []ctfd.ChallengesData
+	}{
+		{"Easiest unsolved challenges", r.Easiest},
+		{"Challenges nobody has solved", r.Unsolved},
+	}
+
+	for _, list := range lists {
+		if len(list.challenges) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n%s:\n", list.title)
+		tw := tabwriter.NewWriter(w, 0, 0, 4, ' ', 0)
+		for _, challenge := range list.challenges {
+			fmt.Fprintf(tw, "  %s\t%s\t%d points\t%d solves\n", challenge.Name, challenge.Category, challenge.Value, challenge.Solves)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	ctfdCmd.AddCommand(ctfdStatsCmd)
+
+	ctfdStatsCmd.Flags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	ctfdStatsCmd.Flags().StringVarP(&opts.Username, "username", "u", "", "Username for CTFd authentication")
+	ctfdStatsCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	ctfdStatsCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
+	ctfdStatsCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+	ctfdStatsCmd.Flags().IntVarP(&statsEasiest, "count", "n", 5, "Number of easiest unsolved challenges to list")
+
+	// viper
+	err := viper.BindPFlag("url", ctfdStatsCmd.Flags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", ctfdStatsCmd.Flags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", ctfdStatsCmd.Flags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("token", ctfdStatsCmd.Flags().Lookup("token"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("skip-check", ctfdStatsCmd.Flags().Lookup("skip-check"))
+	CheckErr(err)
+}
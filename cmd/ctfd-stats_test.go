@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+func testStats() statsReport {
+	challenges := []ctfd.ChallengesData{
+		{ID: 1, Name: "warmup", Category: "web", Value: 100, Solves: 40, SolvedByMe: true},
+		{ID: 2, Name: "sqli", Category: "web", Value: 300, Solves: 12},
+		{ID: 3, Name: "heap", Category: "pwn", Value: 500},
+	}
+	return statsReport{ctfd.NewStats(challenges, nil, []ctfd.Award{{Value: 50}, {Value: -20}}, 5)}
+}
+
+func TestStatsReport_Rows(t *testing.T) {
+	want := [][]string{{"pwn", "1", "0", "500", "0"}, {"web", "2", "1", "400", "100"}}
+	if got := testStats().Rows(); !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestStatsReport_Render(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testStats().Render(&buf); err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+
+	for _, want := range []string{"100/900", "Share of the points: 11.1%", "Score: 130 (100 from challenges, 50 from awards, -20 for hints)", "Easiest unsolved challenges:\n  sqli", "Challenges nobody has solved:\n  heap"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output misses %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"net/url"
+	"os"
 	"strings"
 
 	"github.com/ritchies/ctftool/pkg/ctfd"
@@ -48,14 +49,14 @@ var ctfdSubmitCmd = &cobra.Command{
 		}
 
 		if opts.Username != "" && opts.Password == "" {
-			fmt.Print("Enter your password: ")
+			fmt.Fprint(os.Stderr, "Enter your password: ")
 			var password string
 			fmt.Scanln(&password)
 			opts.Password = strings.TrimSpace(password)
 		}
 
-		if (opts.Username == "" || opts.Password == "") && opts.Token == "" {
-			ShowHelp(cmd, "Either CTFD Username and Password or a Token are required")
+		if (opts.Username == "" || opts.Password == "") && opts.Token == "" && !hasCookies() {
+			ShowHelp(cmd, "Either CTFD Username and Password, a Token or a session cookie are required")
 		}
 
 		credentials := scraper.Credentials{
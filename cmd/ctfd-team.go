@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/ritchies/ctftool/pkg/scraper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var teamOpts struct {
+	Name     string
+	Password string
+	CSV      string
+}
+
+// ctfdTeamCmd represents the team command
+var ctfdTeamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Create or join a team",
+	Long:  `Create or join a team on a CTFd instance in team mode.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Help()
+		CheckErr(err)
+	},
+}
+
+// ctfdTeamCreateCmd represents the team create command
+var ctfdTeamCreateCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Create a team",
+	Long:    `Log in and create a new team, the account becomes the team captain.`,
+	Example: `  ctftool ctfd team create --url https://demo.ctfd.io --username alice --password hunter2 --team pwners --team-password s3cret`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTeam(cmd, ctfd.CreateTeam, "Created")
+	},
+}
+
+// ctfdTeamJoinCmd represents the team join command
+var ctfdTeamJoinCmd = &cobra.Command{
+	Use:   "join",
+	Short: "Join a team",
+	Long: `Log in and join an existing team with its password.
+
+With --csv, every account of the CSV file (name,email,password) logs in and joins the team.`,
+	Example: `  ctftool ctfd team join --url https://demo.ctfd.io --username bob --password letmein --team pwners --team-password s3cret
+  ctftool ctfd team join --url https://demo.ctfd.io --csv teammates.csv --team pwners --team-password s3cret`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTeam(cmd, ctfd.JoinTeam, "Joined")
+	},
+}
+
+func runTeam(cmd *cobra.Command, action func(ctfd.TeamCredentials) error, verb string) {
+	client := ctfd.NewClient()
+	ctfdOptions()
+
+	client.BaseURL = getBaseURL(cmd)
+
+	if teamOpts.Name == "" || teamOpts.Password == "" {
+		ShowHelp(cmd, "A team name and a team password are required")
+	}
+
+	var accounts []*scraper.Credentials
+	if teamOpts.CSV != "" {
+		for _, registration := range readTeammates(teamOpts.CSV) {
+			accounts = append(accounts, &scraper.Credentials{
+				Username: registration.Name,
+				Password: registration.Password,
+			})
+		}
+	} else {
+		if opts.Token != "" && opts.Username == "" {
+			ShowHelp(cmd, "Joining or creating a team requires a username and password, tokens only work with the API")
+		}
+		accounts = append(accounts, getCredentials(cmd))
+	}
+
+	if !opts.SkipCTFDCheck {
+		CheckErr(ctfd.Check())
+	}
+
+	team := ctfd.TeamCredentials{Name: teamOpts.Name, Password: teamOpts.Password}
+
+	var failed int
+	for _, account := range accounts {
+		client.ResetSession()
+		client.Creds = account
+
+		if err := ctfd.Authenticate(); err != nil {
+			log.WithField("name", account.Username).Error(err)
+			failed++
+			continue
+		}
+
+		if err := action(team); err != nil {
+			log.WithField("name", account.Username).Error(err)
+			failed++
+			continue
+		}
+
+		log.WithField("name", account.Username).Infof("%s team %q", verb, team.Name)
+	}
+
+	if failed > 0 {
+		CheckErr(fmt.Errorf("%d of %d accounts failed", failed, len(accounts)))
+	}
+}
+
+func init() {
+	ctfdCmd.AddCommand(ctfdTeamCmd)
+	ctfdTeamCmd.AddCommand(ctfdTeamCreateCmd, ctfdTeamJoinCmd)
+
+	ctfdTeamCmd.PersistentFlags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	ctfdTeamCmd.PersistentFlags().StringVarP(&opts.Username, "username", "u", "", "Username for CTFd authentication")
+	ctfdTeamCmd.PersistentFlags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	ctfdTeamCmd.PersistentFlags().StringVarP(&teamOpts.Name, "team", "", "", "Name of the team")
+	ctfdTeamCmd.PersistentFlags().StringVarP(&teamOpts.Password, "team-password", "", "", "Password of the team")
+	ctfdTeamCmd.PersistentFlags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+	ctfdTeamJoinCmd.Flags().StringVarP(&teamOpts.CSV, "csv", "", "", "CSV file of teammates (name,email,password)")
+
+	// viper
+	err := viper.BindPFlag("url", ctfdTeamCmd.PersistentFlags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", ctfdTeamCmd.PersistentFlags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", ctfdTeamCmd.PersistentFlags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("skip-check", ctfdTeamCmd.PersistentFlags().Lookup("skip-check"))
+	CheckErr(err)
+}
@@ -2,61 +2,293 @@ package cmd
 
 import (
 	"fmt"
-	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ritchies/ctftool/internal/lib"
 	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/ritchies/ctftool/pkg/scraper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var topOpts struct {
+	Count         int
+	ThresholdRank int
+	Rivals        []string
+}
+
 // ctfdTopCmd represents the top command
 var ctfdTopCmd = &cobra.Command{
-	Use:     "top",
-	Short:   "Displays top 10 teams",
-	Long:    `Display the top 10 teams from CTFd`,
-	Example: `  ctftool ctfd top --url https://demo.ctfd.io`,
+	Use:   "top",
+	Short: "Displays the top teams",
+	Long: `Display the top teams of the scoreboard from CTFd. The credentials are
+used to log in first, for scoreboards only visible to logged in users.
+
+With --watch the scoreboard is refreshed every --watch-interval and the
+places gained or lost and the points scored since the previous refresh are
+shown. With --notify a desktop notification is sent when your team gains or
+loses places, when a team enters or leaves the first --threshold-rank places,
+or when one of the --rivals scores. Your team is found with the credentials.`,
+	Example: `  ctftool ctfd top --url https://demo.ctfd.io
+  ctftool ctfd top --count 20 --output json
+  ctftool ctfd top --watch --watch-interval 1m --notify --token <token> --threshold-rank 10 --rivals "pwners,0xdeadbeef"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		client := ctfd.NewClient()
-		uri := viper.GetString("url")
+		ctfdOptions()
 
-		baseURL, err := url.Parse(uri)
-		CheckErr(err)
+		client.BaseURL = getBaseURL(cmd)
+		client.Creds = &scraper.Credentials{
+			Username: opts.Username,
+			Password: opts.Password,
+			Token:    opts.Token,
+		}
 
-		if baseURL.Host == "" {
-			ShowHelp(cmd, fmt.Sprintf("Invalid or empty URL provided: %q", baseURL.String()))
+		// the scoreboard can be private to logged in users
+		if opts.Username != "" && opts.Password != "" && opts.Token == "" {
+			err := ctfd.Authenticate()
+			CheckErr(err)
+			log.Infof("Authenticated as %q", opts.Username)
 		}
 
-		client.BaseURL = baseURL
+		topOpts.ThresholdRank = viper.GetInt("threshold-rank")
+		topOpts.Rivals = viper.GetStringSlice("rivals")
+
+		// the teams just below the threshold are needed to see them pass it
+		count := topOpts.Count
+		if count < topOpts.ThresholdRank+1 {
+			count = topOpts.ThresholdRank + 1
+		}
 
-		teamsData, err := ctfd.ScoreboardTop(10)
+		standings, err := ctfd.TopStandings(count)
 		CheckErr(err)
 
-		for i := 1; i <= 10; i++ {
-			team, err := teamsData.GetTeam(i)
-			CheckErr(err)
+		if !opts.Watch {
+			printResult(newStandingList(standings, topOpts.Count))
+			return
+		}
+
+		printResult(newMovementList(ctfd.CompareStandings(standings, standings), topOpts.Count))
 
-			if team.ID == 0 {
-				continue
+		mine := 0
+		if opts.Token != "" || opts.Password != "" || hasCookies() {
+			team, err := ctfd.MyTeam()
+			CheckWarn(err)
+			if team != nil {
+				mine = team.ID
 			}
+		}
 
-			teamSolves := team.Solves
-			teamScore := 0
-			for _, solve := range teamSolves {
-				teamScore += solve.Value
+		watch("the scoreboard", func() {
+			// a temporary error must not stop watching
+			current, err := ctfd.TopStandings(count)
+			if err != nil {
+				log.Warnf("Checking the scoreboard failed: %v", err)
+				return
 			}
 
-			log.WithField("score", teamScore).Info(
-				fmt.Sprintf("%d. %s", i, team.Name),
-			)
-		}
+			movements := ctfd.CompareStandings(standings, current)
+			for _, alert := range scoreboardAlerts(standings, movements, mine, topOpts.ThresholdRank, topOpts.Rivals) {
+				log.Info(alert)
+				if opts.Notify {
+					CheckWarn(lib.SendNotification("CTFTool", alert))
+				}
+			}
+
+			standings = current
+			printResult(newMovementList(movements, topOpts.Count))
+		})
 	},
 }
 
+// scoreboardAlerts returns the notifications of a refresh of the scoreboard:
+// our team gaining or losing places, a team entering or leaving the first
+// threshold places and a rival scoring. mine is the ID of our team, 0 when
+// unknown, and a threshold of 0 is disabled.
+func scoreboardAlerts(previous []ctfd.Standing, movements []ctfd.Movement, mine int, threshold int, rivals []string) []string {
+	var alerts []string
+
+	current := make(map[int]bool)
+	for _, movement := range movements {
+		current[movement.ID] = true
+
+		if mine != 0 && movement.ID == mine {
+			switch places := movement.Places(); {
+			case places > 0:
+				alerts = append(alerts, fmt.Sprintf("We gained %s, now #%d with %d points", placesText(places), movement.Rank, movement.Score()))
+			case places < 0:
+				alerts = append(alerts, fmt.Sprintf("We lost %s, now #%d with %d points", placesText(-places), movement.Rank, movement.Score()))
+			case movement.PreviousRank == 0:
+				alerts = append(alerts, fmt.Sprintf("We are on the scoreboard at #%d with %d points", movement.Rank, movement.Score()))
+			}
+		}
+
+		if threshold > 0 {
+			inside := movement.Rank <= threshold
+			wasInside := movement.PreviousRank != 0 && movement.PreviousRank <= threshold
+			switch {
+			case inside && !wasInside:
+				alerts = append(alerts, fmt.Sprintf("%s entered the top %d at #%d", movement.Name, threshold, movement.Rank))
+			case !inside && wasInside:
+				alerts = append(alerts, fmt.Sprintf("%s left the top %d, now #%d", movement.Name, threshold, movement.Rank))
+			}
+		}
+
+		if delta := movement.Delta(); delta > 0 && isRival(movement.Name, rivals) {
+			alerts = append(alerts, fmt.Sprintf("Rival %s scored %d points, now #%d with %d points", movement.Name, delta, movement.Rank, movement.Score()))
+		}
+	}
+
+	// teams that fell off the fetched part of the scoreboard
+	for _, standing := range previous {
+		if current[standing.ID] {
+			continue
+		}
+		if threshold > 0 && standing.Rank <= threshold {
+			alerts = append(alerts, fmt.Sprintf("%s left the top %d", standing.Name, threshold))
+		}
+		if mine != 0 && standing.ID == mine {
+			alerts = append(alerts, fmt.Sprintf("We dropped off the top %d", len(movements)))
+		}
+	}
+
+	return alerts
+}
+
+func placesText(places int) string {
+	if places == 1 {
+		return "1 place"
+	}
+	return fmt.Sprintf("%d places", places)
+}
+
+func isRival(name string, rivals []string) bool {
+	for _, rival := range rivals {
+		if strings.EqualFold(strings.TrimSpace(rival), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func newStandingList(standings []ctfd.Standing, count int) standingList {
+	list := standingList{}
+	for _, standing := range standings {
+		if standing.Rank > count {
+			break
+		}
+		list = append(list, standingRow{
+			Rank:  standing.Rank,
+			ID:    standing.ID,
+			Name:  standing.Name,
+			Score: standing.Score(),
+		})
+	}
+	return list
+}
+
+// standingRow is a team as printed by ctfd top.
+type standingRow struct {
+	Rank  int    `json:"rank"`
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// standingList is the output of ctfd top.
+type standingList []standingRow
+
+func (l standingList) Columns() []string {
+	return []string{"rank", "id", "name", "score"}
+}
+
+func (l standingList) Rows() [][]string {
+	var rows [][]string
+	for _, row := range l {
+		rows = append(rows, []string{strconv.Itoa(row.Rank), strconv.Itoa(row.ID), row.Name, strconv.Itoa(row.Score)})
+	}
+	return rows
+}
+
+// movementRow is a team as printed by ctfd top --watch.
+type movementRow struct {
+	standingRow
+	Places int  `json:"places"` // places gained since the previous refresh, negative when lost
+	Delta  int  `json:"delta"`  // points scored since the previous refresh
+	New    bool `json:"new"`    // not on the previous refresh
+}
+
+// movementList is the output of every refresh of ctfd top --watch.
+type movementList []movementRow
+
+func newMovementList(movements []ctfd.Movement, count int) movementList {
+	list := movementList{}
+	for _, movement := range movements {
+		if movement.Rank > count {
+			break
+		}
+		list = append(list, movementRow{
+			standingRow: standingRow{Rank: movement.Rank, ID: movement.ID, Name: movement.Name, Score: movement.Score()},
+			Places:      movement.Places(),
+			Delta:       movement.Delta(),
+			New:         movement.PreviousRank == 0,
+		})
+	}
+	return list
+}
+
+func (l movementList) Columns() []string {
+	return []string{"rank", "move", "id", "name", "score", "delta"}
+}
+
+func (l movementList) Rows() [][]string {
+	var rows [][]string
+	for _, row := range l {
+		move := ""
+		switch {
+		case row.New:
+			move = "new"
+		case row.Places > 0:
+			move = fmt.Sprintf("↑%d", row.Places)
+		case row.Places < 0:
+			move = fmt.Sprintf("↓%d", -row.Places)
+		}
+
+		delta := ""
+		if row.Delta != 0 {
+			delta = fmt.Sprintf("%+d", row.Delta)
+		}
+
+		rows = append(rows, []string{strconv.Itoa(row.Rank), move, strconv.Itoa(row.ID), row.Name, strconv.Itoa(row.Score), delta})
+	}
+	return rows
+}
+
 func init() {
 	ctfdCmd.AddCommand(ctfdTopCmd)
 
 	ctfdTopCmd.Flags().StringVarP(&opts.URL, "url", "u", "", "URL of the CTFd instance")
+	ctfdTopCmd.Flags().StringVarP(&opts.Username, "username", "", "", "Username for CTFd authentication")
+	ctfdTopCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	ctfdTopCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
+	ctfdTopCmd.Flags().IntVarP(&topOpts.Count, "count", "n", 10, "Number of top teams to display")
+	ctfdTopCmd.Flags().BoolVarP(&opts.Watch, "watch", "w", false, "Refresh the scoreboard and show the rank movements")
+	ctfdTopCmd.Flags().DurationVarP(&opts.WatchInterval, "watch-interval", "", time.Minute, "Interval for refreshing the scoreboard")
+	ctfdTopCmd.Flags().BoolVarP(&opts.Notify, "notify", "", false, "Enable desktop notifications")
+	ctfdTopCmd.Flags().IntVarP(&topOpts.ThresholdRank, "threshold-rank", "", 0, "Notify when a team enters or leaves the first places, e.g. 10 for finals")
+	ctfdTopCmd.Flags().StringSliceVarP(&topOpts.Rivals, "rivals", "", nil, "Notify when one of these teams scores (repeatable)")
 
+	// viper
 	err := viper.BindPFlag("url", ctfdTopCmd.Flags().Lookup("url"))
 	CheckErr(err)
+
+	err = viper.BindPFlag("token", ctfdTopCmd.Flags().Lookup("token"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("threshold-rank", ctfdTopCmd.Flags().Lookup("threshold-rank"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("rivals", ctfdTopCmd.Flags().Lookup("rivals"))
+	CheckErr(err)
 }
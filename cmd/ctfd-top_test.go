@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+func standing(rank, id int, name string, score int) ctfd.Standing {
+	return ctfd.Standing{Rank: rank, Team: ctfd.Team{ID: id, Name: name, Solves: []ctfd.Solves{{Value: score}}}}
+}
+
+func TestScoreboardAlerts(t *testing.T) {
+	previous := []ctfd.Standing{
+		standing(1, 1, "alpha", 1000),
+		standing(2, 2, "bravo", 900),
+		standing(3, 3, "us", 800),
+		standing(4, 4, "Rival", 700),
+	}
+
+	tests := []struct {
+		description string
+		current     []ctfd.Standing
+		mine        int
+		threshold   int
+		rivals      []string
+		want        []string
+	}{
+		{
+			description: "nothing changed",
+			current:     previous,
+			mine:        3,
+			threshold:   3,
+			rivals:      []string{"rival"},
+		},
+		{
+			description: "we gained places and pushed a team out of the threshold",
+			current:     []ctfd.Standing{standing(1, 3, "us", 1200), standing(2, 1, "alpha", 1000), standing(3, 2, "bravo", 900), standing(4, 4, "Rival", 700)},
+			mine:        3,
+			threshold:   2,
+			want:        []string{"We gained 2 places, now #1 with 1200 points", "us entered the top 2 at #1", "bravo left the top 2, now #3"},
+		},
+		{
+			description: "a rival scored and passed us",
+			current:     []ctfd.Standing{standing(1, 1, "alpha", 1000), standing(2, 2, "bravo", 900), standing(3, 4, "Rival", 850), standing(4, 3, "us", 800)},
+			mine:        3,
+			rivals:      []string{" rival "},
+			want:        []string{"Rival Rival scored 150 points, now #3 with 850 points", "We lost 1 place, now #4 with 800 points"},
+		},
+		{
+			description: "we fell off the fetched scoreboard",
+			current:     []ctfd.Standing{standing(1, 1, "alpha", 1000), standing(2, 2, "bravo", 900), standing(3, 5, "charlie", 850)},
+			mine:        3,
+			threshold:   3,
+			want:        []string{"charlie entered the top 3 at #3", "us left the top 3", "We dropped off the top 3"},
+		},
+	}
+
+	for _, test := range tests {
+		movements := ctfd.CompareStandings(previous, test.current)
+		got := scoreboardAlerts(previous, movements, test.mine, test.threshold, test.rivals)
+
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.description, got, test.want)
+		}
+	}
+}
+
+func TestMovementList(t *testing.T) {
+	previous := []ctfd.Standing{standing(1, 1, "alpha", 1000), standing(2, 2, "bravo", 900)}
+	current := []ctfd.Standing{standing(1, 2, "bravo", 1100), standing(2, 1, "alpha", 1000), standing(3, 3, "charlie", 50)}
+
+	rows := newMovementList(ctfd.CompareStandings(previous, current), 3).Rows()
+	want := [][]string{
+		{"1", "↑1", "2", "bravo", "1100", "+200"},
+		{"2", "↓1", "1", "alpha", "1000", ""},
+		{"3", "new", "3", "charlie", "50", ""},
+	}
+
+	if !cmp.Equal(rows, want) {
+		t.Errorf("got %v, want %v", rows, want)
+	}
+
+	if got := newMovementList(ctfd.CompareStandings(previous, current), 2); len(got) != 2 {
+		t.Errorf("got %d rows, want the first 2", len(got))
+	}
+}
@@ -4,10 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path"
-	"strings"
-	"sync"
 
-	"github.com/ritchies/ctftool/internal/lib"
 	"github.com/ritchies/ctftool/pkg/ctfd"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -42,55 +39,83 @@ func runWriteups(cmd *cobra.Command, args []string) {
 		log.Infof("Authenticated as %q", opts.Username)
 	}
 
-	processWriteups()
+	layout := challengeLayout(cmd)
+	filter := challengeFilter(cmd, layout)
+
+	if opts.DryRun {
+		challenges, err := ctfd.ListChallenges()
+		CheckErr(err)
+		printPlan(planChallenges(challenges, layout, filter, writeupAction))
+		return
+	}
+
+	report, err := processWriteups(layout, filter)
+	printRun(report, err)
+
+	if code := runExitCode(report, err); code != 0 {
+		os.Exit(code)
+	}
 }
 
-func processWriteups() {
-	// Similar to processChallenges but specific to writeups
-	rl := GetRateLimit()
-	var wg sync.WaitGroup
+// writeupAction updates existing writeups, keeping what was written below "## Writeup".
+func writeupAction(challengePath string) (string, string) {
+	if _, err := os.Stat(path.Join(challengePath, "README.md")); err == nil {
+		return actionUpdate, "writeup section is kept"
+	}
 
-	// List challenges
+	return actionCreate, ""
+}
+
+// processWriteups creates and updates the writeups of the challenges selected by
+// the filter. Failed challenges are collected in the report and don't stop the others.
+func processWriteups(layout *ctfd.Layout, filter *ctfd.Filter) (*runReport, error) {
+	// Similar to processChallenges but specific to writeups
 	challenges, err := ctfd.ListChallenges()
-	CheckErr(err)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, challenge := range SortChallenges(challenges) {
-		wg.Add(1)
+	report := newRunReport()
+	plan := pendingChallenges(planChallenges(challenges, layout, filter, writeupAction), report)
 
-		if options.RateLimit > 0 {
-			rl.Take()
-		}
+	progress := newProgress("Writing", len(plan))
 
-		go func(challenge ctfd.ChallengesData) {
-			name := lib.CleanSlug(challenge.Name, false)
-			category := strings.Split(challenge.Category, " ")[0]
-			category = lib.CleanSlug(category, true)
+	forEachChallenge(plan, opts.Concurrency, func(planned plannedChallenge) {
+		defer progress.ChallengeDone(fmt.Sprintf("%s/%s", planned.Category, planned.Name))
+
+		if err := writeChallenge(planned); err != nil {
+			report.fail(planned, err)
+			removePartial(planned)
+			return
+		}
 
-			if len(category) < 1 || len(name) < 1 {
-				log.Debugf("Skipping challenge %d : invalid category or name", challenge.ID)
-				wg.Done()
-				return
-			}
+		report.add(planned, completedStatus(planned, "created"), "")
+	})
 
-			log.WithField("challenge", fmt.Sprintf("%s/%s", category, name)).Infof("Processing challenge %d", challenge.ID)
+	progress.Close()
 
-			challengePath := path.Join(opts.Output, category, name)
+	if err := layout.Save(opts.Output, challenges); err != nil {
+		return report, err
+	}
 
-			chall, err := ctfd.Challenge(challenge.ID)
-			CheckErr(err)
+	return report, nil
+}
 
-			err = os.MkdirAll(challengePath, os.ModePerm)
-			CheckErr(err)
+// writeChallenge writes the writeup template of a challenge.
+func writeChallenge(planned plannedChallenge) error {
+	log.WithField("challenge", fmt.Sprintf("%s/%s", planned.Category, planned.Name)).Infof("Processing challenge %d", planned.Challenge.ID)
 
-			// get description
-			err = ctfd.GetDescription(chall, challengePath)
-			CheckErr(err)
+	chall, err := ctfd.Challenge(planned.Challenge.ID)
+	if err != nil {
+		return err
+	}
 
-			wg.Done()
-		}(challenge)
+	if err := os.MkdirAll(planned.Path, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create challenge folder: %v", err)
 	}
 
-	wg.Wait()
+	// get description
+	return ctfd.GetDescription(chall, planned.Path)
 }
 
 func init() {
@@ -102,6 +127,10 @@ func init() {
 	ctfdWriteupCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
 	ctfdWriteupCmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Directory for CTFd output (defaults to current directory)")
 	ctfdWriteupCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd connectivity check")
+	ctfdWriteupCmd.Flags().BoolVarP(&opts.UnsolvedOnly, "unsolved", "", false, "Only process challenges that haven't been solved yet")
+	addConcurrencyFlag(ctfdWriteupCmd)
+	addReportFlag(ctfdWriteupCmd)
+	addFilterFlags(ctfdWriteupCmd)
 
 	// viper
 	err := viper.BindPFlag("url", ctfdWriteupCmd.Flags().Lookup("url"))
@@ -121,4 +150,7 @@ func init() {
 
 	err = viper.BindPFlag("skip-check", ctfdWriteupCmd.Flags().Lookup("skip-check"))
 	CheckErr(err)
+
+	err = viper.BindPFlag("concurrency", ctfdWriteupCmd.Flags().Lookup("concurrency"))
+	CheckErr(err)
 }
@@ -53,6 +53,10 @@ func ctfdOptions() {
 	opts.UnsolvedOnly = viper.GetBool("unsolved")
 	opts.Notify = viper.GetBool("notify")
 	opts.MaxFileSize = viper.GetInt64("max-file-size")
+	opts.PathTemplate = viper.GetString("path-template")
+	opts.IndexFormats = viper.GetStringSlice("index-format")
+	opts.Concurrency = viper.GetInt("concurrency")
+	opts.FileConcurrency = viper.GetInt("file-concurrency")
 	options.RateLimit = viper.GetInt("rate-limit")
 }
 
@@ -69,14 +73,14 @@ func getBaseURL(cmd *cobra.Command) *url.URL {
 
 func getCredentials(cmd *cobra.Command) *scraper.Credentials {
 	if opts.Username != "" && opts.Password == "" {
-		fmt.Print("Enter your password: ")
+		fmt.Fprint(os.Stderr, "Enter your password: ")
 		var password string
 		fmt.Scanln(&password)
 		opts.Password = strings.TrimSpace(password)
 	}
 
-	if (opts.Username == "" || opts.Password == "") && opts.Token == "" {
-		ShowHelp(cmd, "Either CTFD Username and Password or a Token are required")
+	if (opts.Username == "" || opts.Password == "") && opts.Token == "" && !hasCookies() {
+		ShowHelp(cmd, "Either CTFD Username and Password, a Token or a session cookie are required")
 	}
 
 	return &scraper.Credentials{
@@ -91,8 +95,13 @@ func setupOutputFolder() string {
 	CheckErr(err)
 	outputFolder := cwd
 
-	if viper.ConfigFileUsed() == "" && opts.Output != "" {
-		outputFolder = path.Join(cwd, opts.Output)
+	// A saved config lives in the output folder itself, unless the output
+	// comes from a profile
+	if (viper.ConfigFileUsed() == "" || options.Profile != "") && opts.Output != "" {
+		outputFolder = opts.Output
+		if !path.IsAbs(outputFolder) {
+			outputFolder = path.Join(cwd, outputFolder)
+		}
 	}
 	return outputFolder
 }
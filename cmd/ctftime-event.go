@@ -1,9 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -89,70 +88,100 @@ var ctftimeEventCmd = &cobra.Command{
 			event, err := ctftime.GetCTFEvent(EventID)
 			CheckErr(err)
 
-			json, err := json.MarshalIndent(event, "", "  ")
-			CheckErr(err)
+			printResult(eventDetail(event))
+			return
+		}
 
-			fmt.Println(string(json))
-		} else {
-			events, err := ctftime.GetCTFEvents()
-			CheckErr(err)
+		events, err := ctftime.GetCTFEvents()
+		CheckErr(err)
 
-			now := time.Now()
-			nextWeek := now.AddDate(0, 0, 7-int(now.Weekday()))
+		now := time.Now()
+		nextWeek := now.AddDate(0, 0, 7-int(now.Weekday()))
 
-			thisWeekEvents := make([]ctftime.Event, 0)
+		thisWeekEvents := weekLineup{}
 
-			for _, event := range events {
-				if event.Start.After(now) && event.Start.Before(nextWeek) {
-					thisWeekEvents = append(thisWeekEvents, event)
-				}
+		for _, event := range events {
+			if event.Start.After(now) && event.Start.Before(nextWeek) {
+				thisWeekEvents = append(thisWeekEvents, event)
 			}
+		}
+
+		printResult(thisWeekEvents)
+	},
+}
+
+// eventDetail is the output of ctftime event for a single event.
+type eventDetail ctftime.Event
+
+func (e eventDetail) Columns() []string {
+	return eventList{}.Columns()
+}
+
+func (e eventDetail) Rows() [][]string {
+	return eventList{ctftime.Event(e)}.Rows()
+}
+
+// weekLineup is the output of ctftime event, the events starting this week.
+type weekLineup []ctftime.Event
+
+func (l weekLineup) Columns() []string {
+	return eventList(l).Columns()
+}
 
-			numberEmojis := []string{string(Keycap1), string(Keycap2), string(Keycap3), string(Keycap4), string(Keycap5), string(Keycap6), string(Keycap7), string(Keycap8), string(Keycap9), string(Keycap10)}
-
-			w := tabwriter.NewWriter(os.Stdout, 0, 4, 0, '\t', 0)
-			fmt.Fprintf(w, "# This week's CTF lineup\n\n")
-			for i, event := range thisWeekEvents {
-				ctfName := event.Title
-				ctftimeURL := event.CTFTimeURL
-				ctfDescription := event.Description
-
-				// if ctf weight != 0, add it to the name
-				if event.Weight != 0 {
-					ctfName = fmt.Sprintf("%s (%.2f)", ctfName, event.Weight)
-				}
-
-				discordChannel := slug.Make(event.Title)
-
-				// make sure the channel name is not too long (100 chars max)
-				if len(discordChannel) > 99 {
-					for words := strings.Split(discordChannel, "-"); len(words) > 2; words = strings.Split(discordChannel, "-") {
-						discordChannel = strings.Join(words[:len(words)-1], "-")
-					}
-				}
-
-				ctfTimes := make(map[string]string)
-				ctfTimes["UTC"] = fmt.Sprintf("%s — %s", event.Start.UTC().Format("Mon, 02 Jan 2006 15:04"), event.Finish.UTC().Format("Mon, 02 Jan 2006 15:04"))
-
-				fmt.Fprintf(w, "%s %s\n", numberEmojis[i], ctfName)
-				fmt.Fprintf(w, "%s #%s\n", SpeechBalloon, discordChannel)
-				fmt.Fprintf(w, "%s %s UTC\n", TwelveOClock, ctfTimes["UTC"])
-				fmt.Fprintf(w, "%s %s\n", TriangularFlag, ctftimeURL)
-				fmt.Fprintf(w, "%s %s\n\n", HyperLink, event.URL)
-
-				// for every line in CTF description, add >>> to the start of the line
-				for _, line := range strings.Split(ctfDescription, "\n") {
-					fmt.Fprintf(w, ">>> %s\n", line)
-				}
-
-				// separator
-				fmt.Fprintf(w, "---\n\n")
+func (l weekLineup) Rows() [][]string {
+	return eventList(l).Rows()
+}
+
+// Render prints the lineup ready to be pasted in Discord.
+func (l weekLineup) Render(out io.Writer) error {
+	numberEmojis := []string{string(Keycap1), string(Keycap2), string(Keycap3), string(Keycap4), string(Keycap5), string(Keycap6), string(Keycap7), string(Keycap8), string(Keycap9), string(Keycap10)}
+
+	w := tabwriter.NewWriter(out, 0, 4, 0, '\t', 0)
+	fmt.Fprintf(w, "# This week's CTF lineup\n\n")
+	for i, event := range l {
+		ctfName := event.Title
+		ctftimeURL := event.CTFTimeURL
+		ctfDescription := event.Description
+
+		// if ctf weight != 0, add it to the name
+		if event.Weight != 0 {
+			ctfName = fmt.Sprintf("%s (%.2f)", ctfName, event.Weight)
+		}
+
+		discordChannel := slug.Make(event.Title)
+
+		// make sure the channel name is not too long (100 chars max)
+		if len(discordChannel) > 99 {
+			for words := strings.Split(discordChannel, "-"); len(words) > 2; words = strings.Split(discordChannel, "-") {
+				discordChannel = strings.Join(words[:len(words)-1], "-")
 			}
+		}
 
-			// flush
-			w.Flush()
+		ctfTimes := make(map[string]string)
+		ctfTimes["UTC"] = fmt.Sprintf("%s — %s", event.Start.UTC().Format("Mon, 02 Jan 2006 15:04"), event.Finish.UTC().Format("Mon, 02 Jan 2006 15:04"))
+
+		number := fmt.Sprintf("%d.", i+1)
+		if i < len(numberEmojis) {
+			number = numberEmojis[i]
 		}
-	},
+
+		fmt.Fprintf(w, "%s %s\n", number, ctfName)
+		fmt.Fprintf(w, "%s #%s\n", SpeechBalloon, discordChannel)
+		fmt.Fprintf(w, "%s %s UTC\n", TwelveOClock, ctfTimes["UTC"])
+		fmt.Fprintf(w, "%s %s\n", TriangularFlag, ctftimeURL)
+		fmt.Fprintf(w, "%s %s\n\n", HyperLink, event.URL)
+
+		// for every line in CTF description, add >>> to the start of the line
+		for _, line := range strings.Split(ctfDescription, "\n") {
+			fmt.Fprintf(w, ">>> %s\n", line)
+		}
+
+		// separator
+		fmt.Fprintf(w, "---\n\n")
+	}
+
+	// flush
+	return w.Flush()
 }
 
 func init() {
@@ -2,8 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -26,85 +27,114 @@ Legend:
  HQ = Hack Quest`,
 	Example: `  ctftool ctftime events --limit 10`,
 	Run: func(cmd *cobra.Command, args []string) {
-		var events []ctftime.Event
-
 		events, err := ctftime.GetCTFEvents()
 		CheckErr(err)
 
-		eventStringsArray := make([]string, 0)
-
+		upcoming := eventList{}
 		for _, event := range events {
-			eventTitle := event.Title
-			eventStart := event.Start
-			eventFinish := event.Finish
-			eventTags := []string{}
-
-			prettyETA := lib.HumanizeTime(eventStart)
-			prettyWeight := lib.FtoaWithDigits(event.Weight, 2)
-
-			switch event.FormatID {
-			case 2:
-				eventTags = append(eventTags, "AD")
-			case 3:
-				eventTags = append(eventTags, "HQ")
+			if len(upcoming) >= limit {
+				break
 			}
-
-			if event.Onsite {
-				eventTags = append(eventTags, "ONSITE")
+			if event.Finish.Before(time.Now()) {
+				continue
 			}
+			upcoming = append(upcoming, event)
+		}
 
-			eventTitle = cleanTitle(eventTitle)
+		printResult(upcoming)
+	},
+}
 
-			if len(eventTags) > 0 {
-				eventTitle = fmt.Sprintf("%s (%s)", eventTitle, strings.Join(eventTags, ", "))
-			}
+// eventList is the output of ctftime events.
+type eventList []ctftime.Event
 
-			switch {
-			case event.Weight == 0 && eventFinish.Sub(eventStart).Hours() < 120:
-				prettyWeight = "TBD"
-				prettyWeight = colorize(prettyWeight, "222", "222")
-			case event.Weight == 0:
-				prettyWeight = "N/A"
-				prettyWeight = colorize(prettyWeight, "223", "223")
-			default:
-				prettyWeight = colorize(prettyWeight, "235", "252")
-			}
+func (l eventList) Columns() []string {
+	return []string{"id", "weight", "title", "start", "finish"}
+}
 
-			if ctftime.IsActive(event) {
-				prettyETA = lib.RelativeTime(eventFinish, time.Now(), "ago", "left")
-
-				if eventFinish.Sub(eventStart).Hours() > 1 && eventFinish.Sub(eventStart).Hours() < 120 {
-					prettyETA = colorize(fmt.Sprintf("%s - active", prettyETA), "#00ff00", "#00ff00")
-				} else if eventFinish.Sub(eventStart).Hours() >= 120 {
-					prettyETA = colorize(fmt.Sprintf("%s - active", prettyETA), "#ffa500", "#ffa500")
-				}
-			} else {
-				if event.Finish.Before(time.Now()) {
-					continue
-				}
-
-				prettyEND := lib.FtoaWithDigits(eventFinish.Sub(eventStart).Hours(), 1)
-				prettyETA = fmt.Sprintf("%s for %s hours", prettyETA, prettyEND)
-			}
+func (l eventList) Rows() [][]string {
+	var rows [][]string
+	for _, event := range l {
+		rows = append(rows, []string{
+			strconv.FormatUint(event.ID, 10),
+			lib.FtoaWithDigits(event.Weight, 2),
+			event.Title,
+			event.Start.Format(time.RFC3339),
+			event.Finish.Format(time.RFC3339),
+		})
+	}
+	return rows
+}
 
-			eventStringsArray = append(eventStringsArray, fmt.Sprintf("%d \t%s \t%s \t(%s)", event.ID, prettyWeight, eventTitle, prettyETA))
+// Render prints the events with a short title, the time until they start or
+// end and their weight, colored by whether it is known yet.
+func (l eventList) Render(w io.Writer) error {
+	eventStringsArray := make([]string, 0)
+
+	for _, event := range l {
+		eventTitle := event.Title
+		eventStart := event.Start
+		eventFinish := event.Finish
+		eventTags := []string{}
+
+		prettyETA := lib.HumanizeTime(eventStart)
+		prettyWeight := lib.FtoaWithDigits(event.Weight, 2)
+
+		switch event.FormatID {
+		case 2:
+			eventTags = append(eventTags, "AD")
+		case 3:
+			eventTags = append(eventTags, "HQ")
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', tabwriter.StripEscape)
+		if event.Onsite {
+			eventTags = append(eventTags, "ONSITE")
+		}
 
-		// ID WEIGHT TITLE ETA
-		fmt.Fprintln(w, "ID\tWEIGHT\tTITLE\tETA")
-		fmt.Fprintln(w, "----\t-----\t-----\t---")
+		eventTitle = cleanTitle(eventTitle)
 
-		for i, eventString := range eventStringsArray {
-			if i >= limit {
-				break
+		if len(eventTags) > 0 {
+			eventTitle = fmt.Sprintf("%s (%s)", eventTitle, strings.Join(eventTags, ", "))
+		}
+
+		switch {
+		case event.Weight == 0 && eventFinish.Sub(eventStart).Hours() < 120:
+			prettyWeight = "TBD"
+			prettyWeight = colorize(prettyWeight, "222", "222")
+		case event.Weight == 0:
+			prettyWeight = "N/A"
+			prettyWeight = colorize(prettyWeight, "223", "223")
+		default:
+			prettyWeight = colorize(prettyWeight, "235", "252")
+		}
+
+		if ctftime.IsActive(event) {
+			prettyETA = lib.RelativeTime(eventFinish, time.Now(), "ago", "left")
+
+			if eventFinish.Sub(eventStart).Hours() > 1 && eventFinish.Sub(eventStart).Hours() < 120 {
+				prettyETA = colorize(fmt.Sprintf("%s - active", prettyETA), "#00ff00", "#00ff00")
+			} else if eventFinish.Sub(eventStart).Hours() >= 120 {
+				prettyETA = colorize(fmt.Sprintf("%s - active", prettyETA), "#ffa500", "#ffa500")
 			}
-			fmt.Fprintln(w, eventString)
+		} else {
+			prettyEND := lib.FtoaWithDigits(eventFinish.Sub(eventStart).Hours(), 1)
+			prettyETA = fmt.Sprintf("%s for %s hours", prettyETA, prettyEND)
 		}
-		w.Flush()
 
-	},
+		eventStringsArray = append(eventStringsArray, fmt.Sprintf("%d \t%s \t%s \t(%s)", event.ID, prettyWeight, eventTitle, prettyETA))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 4, ' ', tabwriter.StripEscape)
+
+	// ID WEIGHT TITLE ETA
+	fmt.Fprintln(tw, "ID\tWEIGHT\tTITLE\tETA")
+	fmt.Fprintln(tw, "----\t-----\t-----\t---")
+
+	for _, eventString := range eventStringsArray {
+		fmt.Fprintln(tw, eventString)
+	}
+
+	return tw.Flush()
 }
 
 var limit int
@@ -1,9 +1,11 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 
+	"github.com/ritchies/ctftool/internal/lib"
 	"github.com/ritchies/ctftool/pkg/ctftime"
 	"github.com/spf13/cobra"
 )
@@ -29,14 +31,46 @@ var ctftimeTeamCmd = &cobra.Command{
 		team, err := ctftime.GetCTFTeam(TeamID)
 		CheckErr(err)
 
-		// pretty print the json result
-		json, err := json.MarshalIndent(team, "", "  ")
-		CheckErr(err)
-
-		fmt.Println(string(json))
+		printResult(teamDetail(team))
 	},
 }
 
+// teamDetail is the output of ctftime team, its rows are the rating of the
+// team by year, the latest first.
+type teamDetail ctftime.CTFTeam
+
+func (t teamDetail) Columns() []string {
+	return []string{"id", "name", "country", "year", "place", "points", "country_place"}
+}
+
+func (t teamDetail) Rows() [][]string {
+	var years []string
+	for year := range t.Rating {
+		years = append(years, year)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(years)))
+
+	var rows [][]string
+	for _, year := range years {
+		rating := t.Rating[year]
+		rows = append(rows, []string{
+			strconv.Itoa(t.ID),
+			t.Name,
+			t.Country,
+			year,
+			strconv.Itoa(rating.RatingPlace),
+			lib.FtoaWithDigits(rating.RatingPoints, 2),
+			strconv.Itoa(rating.CountryPlace),
+		})
+	}
+
+	if len(rows) == 0 {
+		rows = append(rows, []string{strconv.Itoa(t.ID), t.Name, t.Country, "", "", "", ""})
+	}
+
+	return rows
+}
+
 func init() {
 	ctftimeCmd.AddCommand(ctftimeTeamCmd)
 
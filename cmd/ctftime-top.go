@@ -1,11 +1,10 @@
 package cmd
 
 import (
-	"fmt"
+	"strconv"
 
 	"github.com/ritchies/ctftool/internal/lib"
 	"github.com/ritchies/ctftool/pkg/ctftime"
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -18,20 +17,43 @@ var ctftimeTopCmd = &cobra.Command{
 		teams, err := ctftime.GetTopTeams()
 		CheckErr(err)
 
+		top := topTeamList{}
 		for i, team := range teams {
-			teamID := team.TeamID
-			teamName := team.TeamName
-			teamPoints := team.Points
-
-			log.WithFields(logrus.Fields{
-				"id":     teamID,
-				"name":   teamName,
-				"points": lib.FtoaWithDigits(teamPoints, 2),
-			}).Info(fmt.Sprintf("%d. %s", i+1, teamName))
+			top = append(top, topTeamRow{
+				Rank:   i + 1,
+				ID:     team.TeamID,
+				Name:   team.TeamName,
+				Points: team.Points,
+			})
 		}
+
+		printResult(top)
 	},
 }
 
+// topTeamRow is a team as printed by ctftime top.
+type topTeamRow struct {
+	Rank   int     `json:"rank"`
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Points float64 `json:"points"`
+}
+
+// topTeamList is the output of ctftime top.
+type topTeamList []topTeamRow
+
+func (l topTeamList) Columns() []string {
+	return []string{"rank", "id", "name", "points"}
+}
+
+func (l topTeamList) Rows() [][]string {
+	var rows [][]string
+	for _, row := range l {
+		rows = append(rows, []string{strconv.Itoa(row.Rank), strconv.Itoa(row.ID), row.Name, lib.FtoaWithDigits(row.Points, 2)})
+	}
+	return rows
+}
+
 func init() {
 	ctftimeCmd.AddCommand(ctftimeTopCmd)
 }
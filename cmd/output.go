@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	outputFormat   string // format of the command output, one of outputFormats
+	outputTemplate string // Go template of --output template
+
+	// outputFormats are the formats of --output
+	outputFormats = []string{"table", "json", "yaml", "csv", "template"}
+)
+
+// formatFlagName is the flag selecting the output format of the commands
+// whose --output is a directory, like download.
+const formatFlagName = "format"
+
+// tabular is implemented by the results of commands, its columns and rows
+// make the table and the CSV output.
+type tabular interface {
+	Columns() []string
+	Rows() [][]string
+}
+
+// renderer is implemented by results that are printed for the terminal
+// instead of as a plain table.
+type renderer interface {
+	Render(w io.Writer) error
+}
+
+// printResult prints the result of a command to stdout in the --output
+// format.
+func printResult(result tabular) {
+	CheckErr(writeResult(os.Stdout, outputFormat, outputTemplate, result))
+}
+
+// writeResult writes the result in a format of outputFormats. JSON and YAML
+// encode the result itself, the template is executed on the JSON output,
+// once for every item when it is a list.
+func writeResult(w io.Writer, format string, text string, result tabular) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "yaml":
+		return writeYAML(w, result)
+	case "csv":
+		return writeCSV(w, result)
+	case "template":
+		return writeTemplate(w, text, result)
+	}
+
+	if r, ok := result.(renderer); ok {
+		return r.Render(w)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(result.Columns(), "\t")))
+	for _, row := range result.Rows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// writeYAML writes the result as YAML with the keys of its JSON encoding, in
+// the same order.
+func writeYAML(w io.Writer, result tabular) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	// JSON is YAML, only the flow style and the quotes are dropped
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	clearStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// writeCSV writes the columns and rows of the result as CSV, fields a
+// spreadsheet would run as a formula are escaped.
+func writeCSV(w io.Writer, result tabular) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(result.Columns()); err != nil {
+		return err
+	}
+
+	for _, row := range result.Rows() {
+		record := make([]string, len(row))
+		for i, field := range row {
+			record[i] = field
+			if _, err := strconv.ParseFloat(field, 64); err != nil {
+				record[i] = ctfd.CSVField(field)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTemplate executes the template on the JSON output of the result, so
+// the fields are named like in --output json.
+func writeTemplate(w io.Writer, text string, result tabular) error {
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid --template: %v", err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	for _, item := range items {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, item); err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// usesOutputFormat reports whether --output of the command is the format of
+// its output, and not a directory like for download.
+func usesOutputFormat(cmd *cobra.Command) bool {
+	flag := cmd.LocalFlags().Lookup("output")
+	return flag == nil || flag == outputFlag
+}
+
+// outputFormatFlag returns the name of the flag selecting the output format
+// of the command, --output or --format when --output is a directory. It is
+// empty for commands without results, like tui.
+func outputFormatFlag(cmd *cobra.Command) string {
+	if usesOutputFormat(cmd) {
+		return "output"
+	}
+	if cmd.Flags().Lookup(formatFlagName) != nil {
+		return formatFlagName
+	}
+	return ""
+}
+
+// checkOutputFormat validates the output format and --template, --template
+// alone selects the template format.
+func checkOutputFormat(cmd *cobra.Command) error {
+	name := outputFormatFlag(cmd)
+	if name == "" {
+		return nil
+	}
+
+	if name == formatFlagName && opts.ReportJSON && !cmd.Flags().Changed(name) {
+		outputFormat = "json"
+	}
+
+	if outputTemplate != "" && !cmd.Flags().Changed(name) {
+		outputFormat = "template"
+	}
+
+	if !contains(outputFormats, outputFormat) {
+		return fmt.Errorf("invalid --%s %q, expected one of %s", name, outputFormat, strings.Join(outputFormats, ", "))
+	}
+
+	if outputFormat == "template" && outputTemplate == "" {
+		return fmt.Errorf("--%s template requires a --template", name)
+	}
+
+	return nil
+}
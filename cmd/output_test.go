@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func testStandings() standingList {
+	return standingList{
+		{Rank: 1, ID: 7, Name: "=HYPERLINK()", Score: 1200},
+		{Rank: 2, ID: 3, Name: "pwners", Score: -50},
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	tests := []struct {
+		description string
+		format      string
+		template    string
+		want        string
+	}{
+		{
+			description: "table",
+			format:      "table",
+			want:        "RANK    ID    NAME            SCORE\n1       7     =HYPERLINK()    1200\n2       3     pwners          -50\n",
+		},
+		{
+			description: "csv escapes formulas but not numbers",
+			format:      "csv",
+			want:        "rank,id,name,score\n1,7,'=HYPERLINK(),1200\n2,3,pwners,-50\n",
+		},
+		{
+			description: "yaml keeps the JSON keys in order",
+			format:      "yaml",
+			want:        "- rank: 1\n  id: 7\n  name: =HYPERLINK()\n  score: 1200\n- rank: 2\n  id: 3\n  name: pwners\n  score: -50\n",
+		},
+		{
+			description: "template runs on every item",
+			format:      "template",
+			template:    "{{.rank}} {{.name}}",
+			want:        "1 =HYPERLINK()\n2 pwners\n",
+		},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := writeResult(&buf, test.format, test.template, testStandings()); err != nil {
+			t.Fatalf("%s: writeResult() returned error: %v", test.description, err)
+		}
+
+		if got := buf.String(); got != test.want {
+			t.Errorf("%s: got\n%q\nwant\n%q", test.description, got, test.want)
+		}
+	}
+}
+
+func TestWriteResult_Decodes(t *testing.T) {
+	for _, format := range []string{"json", "yaml"} {
+		var buf bytes.Buffer
+		if err := writeResult(&buf, format, "", testStandings()); err != nil {
+			t.Fatalf("%s: writeResult() returned error: %v", format, err)
+		}
+
+		var decoded []map[string]interface{}
+		var err error
+		if format == "json" {
+			err = json.Unmarshal(buf.Bytes(), &decoded)
+		} else {
+			err = yaml.Unmarshal(buf.Bytes(), &decoded)
+		}
+		if err != nil {
+			t.Fatalf("%s: output does not decode: %v", format, err)
+		}
+
+		if len(decoded) != 2 || decoded[1]["name"] != "pwners" {
+			t.Errorf("%s: got %v", format, decoded)
+		}
+	}
+}
+
+func TestWriteResult_SingleValue(t *testing.T) {
+	info := versionInfo{Version: "v1.2.3", Commit: "abc", Date: "today", BuiltBy: "make"}
+
+	var buf bytes.Buffer
+	if err := writeResult(&buf, "template", "{{.version}} ({{.commit}})", info); err != nil {
+		t.Fatalf("writeResult() returned error: %v", err)
+	}
+	if got, want := buf.String(), "v1.2.3 (abc)\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := writeResult(&buf, "csv", "", info); err != nil {
+		t.Fatalf("writeResult() returned error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if want := [][]string{{"version", "commit", "date", "built_by"}, {"v1.2.3", "abc", "today", "make"}}; !cmp.Equal(records, want) {
+		t.Errorf("got %v, want %v", records, want)
+	}
+}
+
+func TestWriteResult_InvalidTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeResult(&buf, "template", "{{.name", testStandings()); err == nil {
+		t.Error("writeResult() returned no error for an invalid template")
+	}
+}
+
+func TestOutputFlagShadowedByDownload(t *testing.T) {
+	if usesOutputFormat(ctfdDownloadCmd) {
+		t.Error("--output of download is the global output format, want its output directory")
+	}
+
+	if !usesOutputFormat(ctfdTopCmd) {
+		t.Error("--output of ctfd top is not the global output format")
+	}
+
+	for _, cmd := range []*cobra.Command{ctfdDownloadCmd, ctfdWriteupCmd} {
+		if got := outputFormatFlag(cmd); got != formatFlagName {
+			t.Errorf("%s: got format flag %q, want --%s", cmd.Name(), got, formatFlagName)
+		}
+	}
+
+	if got := outputFormatFlag(tuiCmd); got != "" {
+		t.Errorf("tui: got format flag %q, want none", got)
+	}
+}
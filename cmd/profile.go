@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ritchies/ctftool/internal/lib"
+	"github.com/spf13/cobra"
+)
+
+var profileOpts struct {
+	URL       string
+	Username  string
+	Password  string
+	Token     string
+	Output    string
+	RateLimit int
+	Notify    bool
+	Use       bool
+}
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named profiles",
+	Long: `Manage named profiles stored in the config file.
+
+Each profile has its own URL, credentials, output directory, rate limit and
+notification settings. Select a profile with --profile, CTFTOOL_PROFILE or
+'ctftool profile use'. Any setting can be overridden with a flag or with a
+CTFTOOL_ prefixed environment variable (e.g. CTFTOOL_TOKEN).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		profileListCmd.Run(cmd, args)
+	},
+}
+
+// profileAddCmd represents the profile add command
+var profileAddCmd = &cobra.Command{
+	Use:     "add <name>",
+	Aliases: []string{"set"},
+	Short:   "Add or update a profile",
+	Example: `  ctftool profile add demo --url https://demo.ctfd.io --token abcdef12356 --output demo --use`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		config, err := lib.LoadConfigFile(configFilePath())
+		CheckErr(err)
+
+		values := lib.Profile{}
+		flags := map[string]interface{}{
+			"url":        profileOpts.URL,
+			"username":   profileOpts.Username,
+			"password":   profileOpts.Password,
+			"token":      profileOpts.Token,
+			"output":     profileOpts.Output,
+			"rate-limit": profileOpts.RateLimit,
+			"notify":     profileOpts.Notify,
+		}
+		for key, value := range flags {
+			if cmd.Flags().Changed(key) {
+				values[key] = value
+			}
+		}
+
+		config.SetProfile(name, values)
+
+		if profileOpts.Use {
+			CheckErr(config.SetCurrentProfile(name))
+		}
+
+		CheckErr(config.Save())
+
+		log.WithField("file", config.Path).Infof("Saved profile %q", name)
+	},
+}
+
+// profileUseCmd represents the profile use command
+var profileUseCmd = &cobra.Command{
+	Use:     "use <name>",
+	Short:   "Select the default profile",
+	Example: `  ctftool profile use demo`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := lib.LoadConfigFile(configFilePath())
+		CheckErr(err)
+
+		CheckErr(config.SetCurrentProfile(args[0]))
+		CheckErr(config.Save())
+
+		log.Infof("Switched to profile %q", args[0])
+	},
+}
+
+// profileListCmd represents the profile list command
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List profiles",
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := lib.LoadConfigFile(configFilePath())
+		CheckErr(err)
+
+		profiles := config.Profiles()
+		if len(profiles) == 0 {
+			log.Info("No profiles found, create one with 'ctftool profile add'")
+		}
+
+		rows := profileList{}
+		for _, name := range config.ProfileNames() {
+			profile := profiles[name]
+			rows = append(rows, profileRow{
+				Name:    name,
+				Current: name == config.CurrentProfile(),
+				URL:     fmt.Sprint(valueOrEmpty(profile["url"])),
+				Output:  fmt.Sprint(valueOrEmpty(profile["output"])),
+			})
+		}
+
+		printResult(rows)
+	},
+}
+
+// profileRow is a profile as printed by profile list, without its credentials.
+type profileRow struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	URL     string `json:"url"`
+	Output  string `json:"output"`
+}
+
+// profileList is the output of profile list.
+type profileList []profileRow
+
+func (l profileList) Columns() []string {
+	return []string{"current", "name", "url", "output"}
+}
+
+func (l profileList) Rows() [][]string {
+	var rows [][]string
+	for _, row := range l {
+		current := ""
+		if row.Current {
+			current = "*"
+		}
+		rows = append(rows, []string{current, row.Name, row.URL, row.Output})
+	}
+	return rows
+}
+
+// profileRemoveCmd represents the profile remove command
+var profileRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm", "delete"},
+	Short:   "Remove a profile",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := lib.LoadConfigFile(configFilePath())
+		CheckErr(err)
+
+		CheckErr(config.RemoveProfile(args[0]))
+		CheckErr(config.Save())
+
+		log.Infof("Removed profile %q", args[0])
+	},
+}
+
+func valueOrEmpty(value interface{}) interface{} {
+	if value == nil {
+		return ""
+	}
+	return value
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileAddCmd, profileUseCmd, profileListCmd, profileRemoveCmd)
+
+	profileAddCmd.Flags().StringVar(&profileOpts.URL, "url", "", "URL of the CTFd instance")
+	profileAddCmd.Flags().StringVarP(&profileOpts.Username, "username", "u", "", "Username for CTFd authentication")
+	profileAddCmd.Flags().StringVarP(&profileOpts.Password, "password", "p", "", "Password for CTFd authentication")
+	profileAddCmd.Flags().StringVarP(&profileOpts.Token, "token", "t", "", "Authentication token for CTFd")
+	profileAddCmd.Flags().StringVarP(&profileOpts.Output, "output", "o", "", "Directory for CTFd output")
+	profileAddCmd.Flags().IntVar(&profileOpts.RateLimit, "rate-limit", 10, "Limit the number of API requests per second")
+	profileAddCmd.Flags().BoolVar(&profileOpts.Notify, "notify", false, "Enable desktop notifications")
+	profileAddCmd.Flags().BoolVar(&profileOpts.Use, "use", false, "Select the profile after saving it")
+}
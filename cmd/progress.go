@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+// maxProgressFiles is the number of active downloads shown below the progress line.
+const maxProgressFiles = 6
+
+type fileProgress struct {
+	name    string
+	size    int64
+	written int64
+}
+
+// progressDisplay shows the progress of download and writeups. On a terminal
+// it redraws a live summary below the log lines on stderr, otherwise it logs a
+// line per challenge and per file. It implements ctfd.Progress to follow file downloads.
+type progressDisplay struct {
+	mu      sync.Mutex
+	out     io.Writer
+	logOut  io.Writer
+	live    bool
+	verb    string
+	total   int
+	done    int
+	started time.Time
+	files   []*fileProgress
+	drawn   int // lines drawn by the last redraw
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// newProgress starts the progress display of total challenges. It has to be closed.
+func newProgress(verb string, total int) *progressDisplay {
+	p := &progressDisplay{
+		out:     os.Stderr,
+		verb:    verb,
+		total:   total,
+		started: time.Now(),
+		live:    total > 0 && isatty.IsTerminal(os.Stdout.Fd()) && isatty.IsTerminal(os.Stderr.Fd()),
+	}
+
+	if p.live {
+		// log lines are written above the live summary
+		p.logOut = log.Out
+		log.SetOutput(p)
+
+		p.stop = make(chan struct{})
+		p.stopped = make(chan struct{})
+		go p.refresh()
+	}
+
+	ctfd.SetProgress(p)
+
+	return p
+}
+
+func (p *progressDisplay) refresh() {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	defer close(p.stopped)
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.clear()
+			p.draw()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Write writes log lines above the live summary.
+func (p *progressDisplay) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.clear()
+	n, err := p.out.Write(b)
+	p.draw()
+
+	return n, err
+}
+
+// Start implements ctfd.Progress.
+func (p *progressDisplay) Start(file string, size int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.files = append(p.files, &fileProgress{name: file, size: size})
+}
+
+// Add implements ctfd.Progress.
+func (p *progressDisplay) Add(file string, n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, f := range p.files {
+		if f.name == file {
+			f.written += n
+			return
+		}
+	}
+}
+
+// Done implements ctfd.Progress.
+func (p *progressDisplay) Done(file string, err error) {
+	p.mu.Lock()
+	var written int64
+	for i, f := range p.files {
+		if f.name == file {
+			written = f.written
+			p.files = append(p.files[:i], p.files[i+1:]...)
+			break
+		}
+	}
+	p.mu.Unlock()
+
+	if !p.live && err == nil {
+		log.WithField("file", file).Debugf("Downloaded %s", humanizeBytes(written))
+	}
+}
+
+// ChallengeDone counts a processed challenge, successful or not.
+func (p *progressDisplay) ChallengeDone(name string) {
+	p.mu.Lock()
+	p.done++
+	done, eta := p.done, p.eta()
+	p.mu.Unlock()
+
+	if !p.live {
+		log.WithField("challenge", name).Infof("%d/%d challenges done, ETA %s", done, p.total, eta)
+	}
+}
+
+// Close stops the display and restores the log output.
+func (p *progressDisplay) Close() {
+	ctfd.SetProgress(nil)
+
+	if !p.live {
+		return
+	}
+
+	close(p.stop)
+	<-p.stopped
+
+	p.mu.Lock()
+	p.clear()
+	p.mu.Unlock()
+
+	log.SetOutput(p.logOut)
+}
+
+// eta estimates the remaining time from the average time per challenge.
+func (p *progressDisplay) eta() string {
+	if p.done == 0 {
+		return "unknown"
+	}
+	if p.done >= p.total {
+		return "0s"
+	}
+
+	perChallenge := time.Since(p.started) / time.Duration(p.done)
+	return (perChallenge * time.Duration(p.total-p.done)).Round(time.Second).String()
+}
+
+// clear erases the lines of the last redraw, the caller holds the lock.
+func (p *progressDisplay) clear() {
+	if p.drawn > 0 {
+		fmt.Fprintf(p.out, "\033[%dA\033[J", p.drawn)
+		p.drawn = 0
+	}
+}
+
+// draw writes the live summary, the caller holds the lock.
+func (p *progressDisplay) draw() {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: %d/%d challenges done, %d files in progress, ETA %s\n", p.verb, p.done, p.total, len(p.files), p.eta())
+	for i, f := range p.files {
+		if i == maxProgressFiles {
+			fmt.Fprintf(&b, "  ... %d more\n", len(p.files)-maxProgressFiles)
+			break
+		}
+
+		size, percent := "?", ""
+		if f.size > 0 {
+			size = humanizeBytes(f.size)
+			percent = fmt.Sprintf(" %3d%%", f.written*100/f.size)
+		}
+		fmt.Fprintf(&b, "  %-40s %9s / %-9s%s\n", truncate(f.name, 40), humanizeBytes(f.written), size, percent)
+	}
+
+	p.drawn = strings.Count(b.String(), "\n")
+	fmt.Fprint(p.out, b.String())
+}
+
+func humanizeBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
+
+// truncate shortens s to n runes, so a line never wraps and breaks the redraw.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+const (
+	statusSkipped = "skipped"
+	statusFailed  = "failed"
+)
+
+// reportEntry is the outcome of a challenge in a run of download or writeups.
+type reportEntry struct {
+	ID        int64  `json:"id"`
+	Challenge string `json:"challenge"`
+	Category  string `json:"category"`
+	Path      string `json:"path,omitempty"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// runReport collects the outcome of every challenge, so one failing challenge
+// doesn't stop the others and every failure is listed at the end.
+type runReport struct {
+	mu      sync.Mutex
+	Entries []reportEntry  `json:"challenges"`
+	Counts  map[string]int `json:"counts"`
+}
+
+func newRunReport() *runReport {
+	return &runReport{Counts: make(map[string]int)}
+}
+
+// add records the outcome of a planned challenge.
+func (r *runReport) add(planned plannedChallenge, status string, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Entries = append(r.Entries, reportEntry{
+		ID:        planned.Challenge.ID,
+		Challenge: planned.Challenge.Name,
+		Category:  planned.Category,
+		Path:      planned.Path,
+		Status:    status,
+		Reason:    reason,
+	})
+	r.Counts[status]++
+}
+
+// fail records a challenge that failed with err.
+func (r *runReport) fail(planned plannedChallenge, err error) {
+	log.WithField("challenge", fmt.Sprintf("%s/%s", planned.Category, planned.Name)).Warn(err)
+	r.add(planned, statusFailed, err.Error())
+}
+
+// Failed reports whether any challenge failed.
+func (r *runReport) Failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.Counts[statusFailed] > 0
+}
+
+// Summary returns the count of every status, like "3 downloaded, 1 failed".
+func (r *runReport) Summary() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var statuses []string
+	for status := range r.Counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	var parts []string
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%d %s", r.Counts[status], status))
+	}
+
+	if len(parts) == 0 {
+		return "nothing to do"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// sort sorts the entries of the report by challenge ID.
+func (r *runReport) sort() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sort.SliceStable(r.Entries, func(i, j int) bool {
+		return r.Entries[i].ID < r.Entries[j].ID
+	})
+}
+
+func (r *runReport) Columns() []string {
+	return []string{"status", "id", "challenge", "category", "path", "reason"}
+}
+
+func (r *runReport) Rows() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rows [][]string
+	for _, entry := range r.Entries {
+		rows = append(rows, []string{entry.Status, strconv.FormatInt(entry.ID, 10), entry.Challenge, entry.Category, entry.Path, entry.Reason})
+	}
+	return rows
+}
+
+// Render prints the report as a table followed by the count of every status.
+func (r *runReport) Render(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tID\tCHALLENGE\tPATH\tREASON")
+
+	for _, entry := range r.Entries {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", entry.Status, entry.ID, entry.Challenge, entry.Path, entry.Reason)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%s\n", r.Summary())
+	return err
+}
+
+// printRun prints the report of a run to stdout in the --format format, and
+// the error that stopped it.
+func printRun(report *runReport, err error) {
+	if report != nil {
+		report.sort()
+		printResult(report)
+	}
+
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// runExitCode is the exit code of a run, 1 when it stopped on an error or a challenge failed.
+func runExitCode(report *runReport, err error) int {
+	if err != nil || report == nil || report.Failed() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+func plannedFor(id int64, name string, action string, challengePath string) plannedChallenge {
+	return plannedChallenge{
+		Challenge: ctfd.ChallengesData{ID: id, Name: name},
+		Category:  "web",
+		Name:      name,
+		Path:      challengePath,
+		Action:    action,
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	report := newRunReport()
+	report.add(plannedFor(3, "three", actionUpdate, "web/three"), completedStatus(plannedFor(3, "three", actionUpdate, ""), "downloaded"), "")
+	report.add(plannedFor(1, "one", actionCreate, "web/one"), completedStatus(plannedFor(1, "one", actionCreate, ""), "downloaded"), "")
+	report.add(plannedFor(2, "two", actionSkip, "web/two"), statusSkipped, "already downloaded")
+
+	if report.Failed() {
+		t.Error("expected the report not to fail without failed challenges")
+	}
+
+	report.fail(plannedFor(4, "four", actionCreate, "web/four"), errors.New("permission denied"))
+
+	if !report.Failed() {
+		t.Error("expected the report to fail")
+	}
+
+	if got, want := report.Summary(), "1 downloaded, 1 failed, 1 skipped, 1 updated"; got != want {
+		t.Errorf("got summary %q, want %q", got, want)
+	}
+
+	report.sort()
+
+	var buf bytes.Buffer
+	if err := writeResult(&buf, "json", "", report); err != nil {
+		t.Fatalf("writeResult() returned error: %v", err)
+	}
+
+	var decoded struct {
+		Challenges []reportEntry  `json:"challenges"`
+		Counts     map[string]int `json:"counts"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("report is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	var ids []int64
+	for _, entry := range decoded.Challenges {
+		ids = append(ids, entry.ID)
+	}
+	if want := []int64{1, 2, 3, 4}; !cmp.Equal(ids, want) {
+		t.Errorf("got challenges %v, want them sorted by ID %v", ids, want)
+	}
+
+	if failed := decoded.Challenges[3]; failed.Status != statusFailed || failed.Reason != "permission denied" {
+		t.Errorf("got failed entry %+v", failed)
+	}
+
+	buf.Reset()
+	if err := writeResult(&buf, "table", "", report); err != nil {
+		t.Fatalf("writeResult() returned error: %v", err)
+	}
+
+	for _, want := range []string{"STATUS", "failed", "permission denied", "1 downloaded, 1 failed, 1 skipped, 1 updated"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected the table to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRunReport_CSV(t *testing.T) {
+	report := newRunReport()
+	report.add(plannedFor(2, "two", actionSkip, "web/two"), statusSkipped, "already downloaded")
+	report.fail(plannedFor(1, "one", actionCreate, "web/one"), errors.New("permission denied"))
+	report.sort()
+
+	var buf bytes.Buffer
+	if err := writeResult(&buf, "csv", "", report); err != nil {
+		t.Fatalf("writeResult() returned error: %v", err)
+	}
+
+	want := "status,id,challenge,category,path,reason\nfailed,1,one,web,web/one,permission denied\nskipped,2,two,web,web/two,already downloaded\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPlanList(t *testing.T) {
+	plan := newPlanList([]plannedChallenge{plannedFor(1, "one", actionCreate, "web/one"), plannedFor(2, "two", actionSkip, "web/two")})
+
+	var buf bytes.Buffer
+	if err := writeResult(&buf, "json", "", plan); err != nil {
+		t.Fatalf("writeResult() returned error: %v", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("plan is not valid JSON: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1]["action"] != actionSkip || decoded[1]["challenge"] != "two" {
+		t.Errorf("got %v", decoded)
+	}
+
+	buf.Reset()
+	if err := writeResult(&buf, "json", "", newPlanList(nil)); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("got %q (%v), want an empty array", buf.String(), err)
+	}
+}
+
+func TestRemovePartial(t *testing.T) {
+	dir := t.TempDir()
+
+	created := path.Join(dir, "created")
+	updated := path.Join(dir, "updated")
+	for _, p := range []string{created, updated} {
+		if err := os.MkdirAll(p, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removePartial(plannedFor(1, "created", actionCreate, created))
+	removePartial(plannedFor(2, "updated", actionUpdate, updated))
+
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("expected the folder of a new challenge to be removed, got %v", err)
+	}
+
+	if _, err := os.Stat(updated); err != nil {
+		t.Errorf("expected the folder of an existing challenge to be kept, got %v", err)
+	}
+}
+
+func TestRunExitCode(t *testing.T) {
+	failed := newRunReport()
+	failed.add(plannedFor(1, "one", actionCreate, ""), statusFailed, "boom")
+
+	skipped := newRunReport()
+	skipped.add(plannedFor(1, "one", actionSkip, ""), statusSkipped, "already downloaded")
+
+	tests := []struct {
+		description string
+		report      *runReport
+		err         error
+		want        int
+	}{
+		{description: "nothing failed", report: skipped, want: 0},
+		{description: "empty run", report: newRunReport(), want: 0},
+		{description: "failed challenge", report: failed, want: 1},
+		{description: "run error", report: skipped, err: errors.New("index"), want: 1},
+		{description: "no report", err: errors.New("list"), want: 1},
+	}
+
+	for _, test := range tests {
+		if got := runExitCode(test.report, test.err); got != test.want {
+			t.Errorf("%s: got exit code %d, want %d", test.description, got, test.want)
+		}
+	}
+}
@@ -2,13 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"path"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/ritchies/ctftool/internal/lib"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/ritchies/ctftool/pkg/ctftime"
+	"github.com/ritchies/ctftool/pkg/scraper"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -16,8 +22,11 @@ import (
 )
 
 var (
-	options = lib.NewOptions() // global options
-	log     = logrus.New()     // global logger
+	options  = lib.NewOptions() // global options
+	log      = logrus.New()     // global logger
+	cassette *scraper.Cassette  // cassette used by --record and --replay
+
+	outputFlag *pflag.Flag // the global --output, download and others shadow it with a directory
 )
 
 func contains(slice []string, str string) bool {
@@ -51,6 +60,7 @@ var rootCmd = &cobra.Command{
 
 It can interact with the CTFTime.org API to retrieve the latest upcoming CTFs,
 and can interact with CTFd API to retrieve the challenges and files.`,
+	PersistentPreRun: bindCommandFlags,
 	Run: func(cmd *cobra.Command, args []string) {
 		if viper.ConfigFileUsed() != "" {
 			ctfdCmd.Run(cmd, args)
@@ -65,6 +75,11 @@ and can interact with CTFd API to retrieve the challenges and files.`,
 			cmd.Aliases = append(cmd.Aliases, cmd.Name())
 		}
 	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if cassette != nil {
+			CheckWarn(cassette.Close())
+		}
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -105,8 +120,30 @@ func init() {
 
 	rootCmd.PersistentFlags().IntVarP(&options.RateLimit, "rate-limit", "", 10, "Limit the number of API requests per second")
 	rootCmd.PersistentFlags().StringVar(&options.ConfigFile, "config", "", "Config file (default is .ctftool.yaml)")
+	rootCmd.PersistentFlags().StringVar(&options.Profile, "profile", "", "Named profile from the config file to use")
 	rootCmd.PersistentFlags().BoolVarP(&options.Debug, "verbose", "v", false, "Verbose logging")
 	rootCmd.PersistentFlags().StringVar(&options.DebugFormat, "log-format", "text", "Format for logging output (text or json)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", fmt.Sprintf("Output format (%s)", strings.Join(outputFormats, "|")))
+	rootCmd.PersistentFlags().StringVar(&outputTemplate, "template", "", "Go template executed on every item of the JSON output")
+	outputFlag = rootCmd.PersistentFlags().Lookup("output")
+
+	rootCmd.PersistentFlags().StringVar(&options.Proxy, "proxy", "", "HTTP(S) or SOCKS5 proxy URL (e.g. http://127.0.0.1:8080)")
+	rootCmd.PersistentFlags().StringSliceVar(&options.CACerts, "ca-cert", nil, "Additional PEM CA bundle to trust (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&options.Insecure, "insecure", false, "Skip TLS certificate verification (dangerous)")
+	rootCmd.PersistentFlags().StringVar(&options.ClientCert, "client-cert", "", "PEM client certificate for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&options.ClientKey, "client-key", "", "PEM client key for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&options.TLSMinVersion, "tls-min-version", "", "Minimum TLS version (1.0, 1.1, 1.2 or 1.3)")
+	rootCmd.PersistentFlags().StringVar(&options.UserAgent, "user-agent", "", "User-Agent header sent with every request")
+	rootCmd.PersistentFlags().StringArrayVar(&options.Headers, "header", nil, "Extra header sent with every request, as 'Name: value' (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&options.Resolve, "resolve", nil, "Resolve host:port to an address, as 'host:port:addr' (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&options.Record, "record", "", "Record every HTTP request and response to a cassette file")
+	rootCmd.PersistentFlags().StringVar(&options.Replay, "replay", "", "Replay HTTP responses from a cassette file without network access")
+	rootCmd.PersistentFlags().BoolVar(&options.Cache, "cache", false, "Cache responses on disk and revalidate them with ETag/Last-Modified")
+	rootCmd.PersistentFlags().StringVar(&options.CacheDir, "cache-dir", "", "Directory of the HTTP cache (default is $XDG_CACHE_HOME/ctftool)")
+	rootCmd.PersistentFlags().DurationVar(&options.CacheTTL, "cache-ttl", 0, "Serve cached responses younger than this without revalidation")
+	rootCmd.PersistentFlags().BoolVar(&options.Offline, "offline", false, "Only serve cached responses, never touch the network")
+	rootCmd.PersistentFlags().StringVar(&options.Cookie, "cookie", "", "Cookie header copied from a logged-in browser, e.g. 'session=...; cf_clearance=...'")
+	rootCmd.PersistentFlags().StringVar(&options.CookieFile, "cookie-file", "", "Netscape cookies.txt file exported from a browser")
 
 	var ctftimeFlags = FlagCategory{
 		Name:  "CTFTime",
@@ -115,20 +152,35 @@ func init() {
 
 	var ctfdFlags = FlagCategory{
 		Name:  "CTFd",
-		Flags: []string{"url", "submission-id", "submission", "unsolved", "skip-check", "output", "overwrite", "max-file-size"},
+		Flags: []string{"url", "submission-id", "submission", "unsolved", "skip-check", "overwrite", "max-file-size", "path-template", "index-format", "concurrency", "file-concurrency", "report-json", "csv", "team", "team-password", "join", "sort", "raw", "count", "since", "until", "width", "height", "dir", "decay"},
+	}
+
+	var outputFlags = FlagCategory{
+		Name:  "Output",
+		Flags: []string{"output", "format", "template", "svg"},
+	}
+
+	var filterFlags = FlagCategory{
+		Name:  "Filters",
+		Flags: []string{"category", "id", "name", "value", "solves", "tag", "dry-run"},
 	}
 
 	var authFlags = FlagCategory{
 		Name:  "Authentication",
-		Flags: []string{"username", "password", "token"},
+		Flags: []string{"username", "password", "token", "email", "registration-code", "field"},
 	}
 
 	var notificationFlags = FlagCategory{
 		Name:  "Notifications",
-		Flags: []string{"notify", "watch", "watch-interval"},
+		Flags: []string{"notify", "watch", "watch-interval", "threshold-rank", "rivals"},
 	}
 
-	var allFlagCategories = []FlagCategory{ctftimeFlags, ctfdFlags, authFlags, notificationFlags}
+	var networkFlags = FlagCategory{
+		Name:  "Network",
+		Flags: []string{"proxy", "ca-cert", "insecure", "client-cert", "client-key", "tls-min-version", "user-agent", "header", "resolve", "record", "replay", "cache", "cache-dir", "cache-ttl", "offline", "cookie", "cookie-file"},
+	}
+
+	var allFlagCategories = []FlagCategory{ctftimeFlags, ctfdFlags, outputFlags, filterFlags, authFlags, notificationFlags, networkFlags}
 
 	usageTemplate := `Usage:
   {{.CommandPath}} [flags]
@@ -244,7 +296,7 @@ Flags:
 		})
 	})
 
-	err := viper.BindPFlags(rootCmd.PersistentFlags())
+	err := bindFlags(rootCmd.PersistentFlags())
 	CheckErr(err)
 }
 
@@ -272,7 +324,10 @@ func initConfig() {
 		viper.SetConfigName(".ctftool")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// read in environment variables that match, e.g. CTFTOOL_URL or CTFTOOL_RATE_LIMIT
+	viper.SetEnvPrefix("ctftool")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	viper.AutomaticEnv()
 
 	// Logrus options
 	log.SetFormatter(&logrus.TextFormatter{
@@ -296,13 +351,275 @@ func initConfig() {
 		log.SetFormatter(&logrus.JSONFormatter{})
 	}
 
-	// Set log output
-	log.Out = os.Stdout
+	// Set log output, stdout is kept for the output of the commands
+	log.Out = os.Stderr
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		log.WithField("config", viper.ConfigFileUsed()).Debug("Using config file")
 	}
+
+	CheckErr(applyProfile())
+	CheckErr(setupHTTPClients())
+}
+
+// setupHTTPClients applies the proxy and TLS settings to the ctfd and ctftime
+// clients.
+func setupHTTPClients() error {
+	options.Proxy = viper.GetString("proxy")
+	options.CACerts = viper.GetStringSlice("ca-cert")
+	options.Insecure = viper.GetBool("insecure")
+	options.ClientCert = viper.GetString("client-cert")
+	options.ClientKey = viper.GetString("client-key")
+	options.TLSMinVersion = viper.GetString("tls-min-version")
+	options.UserAgent = viper.GetString("user-agent")
+	options.Headers = viper.GetStringSlice("header")
+	options.Resolve = viper.GetStringSlice("resolve")
+	options.Record = viper.GetString("record")
+	options.Replay = viper.GetString("replay")
+	options.Cache = viper.GetBool("cache")
+	options.CacheDir = viper.GetString("cache-dir")
+	options.CacheTTL = viper.GetDuration("cache-ttl")
+	options.Offline = viper.GetBool("offline")
+	options.Cookie = viper.GetString("cookie")
+	options.CookieFile = viper.GetString("cookie-file")
+
+	headers, err := parseHeaders(viper.GetStringMapString("headers"), options.Headers)
+	if err != nil {
+		return err
+	}
+
+	if options.Insecure {
+		log.Warn("TLS certificate verification is disabled, connections can be intercepted")
+	}
+
+	switch {
+	case options.Record != "" && options.Replay != "":
+		return fmt.Errorf("--record and --replay cannot be used together")
+	case options.Record != "":
+		if cassette, err = scraper.NewRecordingCassette(options.Record); err != nil {
+			return err
+		}
+		log.WithField("file", options.Record).Info("Recording HTTP requests, credentials and cookies are redacted")
+	case options.Replay != "":
+		if cassette, err = scraper.LoadCassette(options.Replay); err != nil {
+			return err
+		}
+		log.WithField("file", options.Replay).Info("Replaying recorded HTTP responses")
+	}
+
+	cache, err := setupCache()
+	if err != nil {
+		return err
+	}
+
+	transportOptions := &scraper.TransportOptions{
+		Proxy:              options.Proxy,
+		CAFiles:            options.CACerts,
+		InsecureSkipVerify: options.Insecure,
+		ClientCert:         options.ClientCert,
+		ClientKey:          options.ClientKey,
+		MinTLSVersion:      options.TLSMinVersion,
+		UserAgent:          options.UserAgent,
+		Headers:            headers,
+		Resolve:            options.Resolve,
+		Cassette:           cassette,
+		Cache:              cache,
+	}
+
+	for _, client := range []*scraper.Client{ctfd.NewClient(), ctftime.NewClient()} {
+		if err := client.Configure(transportOptions); err != nil {
+			return err
+		}
+	}
+
+	return importCookies(ctfd.NewClient())
+}
+
+// importCookies adds the cookies of --cookie and --cookie-file to the client,
+// so a browser session or a cf_clearance cookie can be reused.
+func importCookies(client *scraper.Client) error {
+	if options.Cookie != "" {
+		client.ImportCookies(scraper.ParseCookieHeader(options.Cookie))
+	}
+
+	if options.CookieFile != "" {
+		f, err := os.Open(options.CookieFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		cookies, err := scraper.ParseCookieFile(f)
+		if err != nil {
+			return err
+		}
+		client.ImportCookies(cookies)
+	}
+
+	return nil
+}
+
+// hasCookies reports whether a browser session was imported with --cookie or --cookie-file.
+func hasCookies() bool {
+	return options.Cookie != "" || options.CookieFile != ""
+}
+
+// bindCommandFlags binds the flags of the command being run to their config
+// keys. Several commands define flags with the same name, and viper only keeps
+// the last binding of a key, so without it a flag changed on any other command
+// would lose against the config file and the profile.
+func bindCommandFlags(cmd *cobra.Command, args []string) {
+	CheckErr(bindFlags(cmd.Flags()))
+
+	if err := checkOutputFormat(cmd); err != nil {
+		ShowHelp(cmd, err)
+	}
+}
+
+// bindFlags binds the flags to their config keys, except the output format
+// flags. The output key of the config file is the directory of download,
+// which shadows the global --output with its own flag.
+func bindFlags(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err == nil && flag != outputFlag && flag.Name != "template" && flag.Name != formatFlagName {
+			err = viper.BindPFlag(flag.Name, flag)
+		}
+	})
+	return err
+}
+
+// applyProfile merges the settings of the selected profile into the config.
+// The profile is taken from --profile (or CTFTOOL_PROFILE) and falls back to
+// the current-profile set with `ctftool profile use`. Flags and environment
+// variables still take precedence over the profile values.
+func applyProfile() error {
+	name := viper.GetString("profile")
+	if name == "" {
+		name = viper.GetString("current-profile")
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	// the profiles are looked up in their map, viper would split a key path
+	// on the dots of a name like my.ctf
+	profile, ok := viper.GetStringMap("profiles")[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("profile %q not found in config file %q", name, configFilePath())
+	}
+
+	if err := viper.MergeConfigMap(cast.ToStringMap(profile)); err != nil {
+		return err
+	}
+
+	options.Profile = name
+	log.WithField("profile", name).Debug("Using profile")
+
+	return nil
+}
+
+// configFilePath returns the config file that is read, or the one that will
+// be created when no config file exists yet.
+func configFilePath() string {
+	if options.ConfigFile != "" {
+		return options.ConfigFile
+	}
+
+	if used := viper.ConfigFileUsed(); used != "" {
+		return used
+	}
+
+	home, err := os.UserHomeDir()
+	CheckErr(err)
+
+	return path.Join(home, ".config", "ctftool", ".ctftool.yaml")
+}
+
+// setupCache returns the HTTP cache, or nil when caching is disabled. The
+// per-endpoint TTLs are read from the cache-ttls map of the config file, e.g.
+//
+//	cache-ttls:
+//	  /api/v1/challenges: 1m
+//	  /api/v1/events: 1h
+func setupCache() (*scraper.Cache, error) {
+	if !options.Cache && !options.Offline {
+		return nil, nil
+	}
+
+	dir := options.CacheDir
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = path.Join(cacheDir, "ctftool")
+	}
+
+	cache := scraper.NewCache(dir, options.CacheTTL)
+	cache.Offline = options.Offline
+	cache.Account = cacheAccount
+
+	for prefix, value := range viper.GetStringMapString("cache-ttls") {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache TTL for %q: %v", prefix, err)
+		}
+		cache.TTLs[prefix] = ttl
+	}
+
+	if options.Offline {
+		log.WithField("dir", dir).Info("Offline mode, only serving cached responses")
+	}
+
+	if err := cache.Prune(); err != nil {
+		log.WithField("dir", dir).Warnf("Failed to prune the cache: %v", err)
+	}
+
+	return cache, nil
+}
+
+// cacheAccount identifies the CTFd account of a request for the cache, by
+// its token or by the instance and the username of a password login. The
+// credentials are only known once the command runs, after the cache is set up.
+func cacheAccount(req *http.Request) string {
+	client := ctfd.NewClient()
+	if client.BaseURL == nil || client.Creds == nil || req.URL.Host != client.BaseURL.Host {
+		return ""
+	}
+
+	switch {
+	case client.Creds.Token != "":
+		return "token " + client.Creds.Token
+	case client.Creds.Username != "":
+		return fmt.Sprintf("user %s %s", client.BaseURL, client.Creds.Username)
+	}
+
+	return ""
+}
+
+// parseHeaders combines the headers map from the config file with the
+// 'Name: value' headers given on the command line, the latter taking
+// precedence.
+func parseHeaders(configHeaders map[string]string, flagHeaders []string) (http.Header, error) {
+	headers := make(http.Header)
+
+	for name, value := range configHeaders {
+		headers.Set(name, value)
+	}
+
+	for _, header := range flagHeaders {
+		name, value, found := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid header %q, expected 'Name: value'", header)
+		}
+		headers.Set(name, strings.TrimSpace(value))
+	}
+
+	return headers, nil
 }
 
 func GetRateLimit() ratelimit.Limiter {
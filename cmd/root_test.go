@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestBindCommandFlags(t *testing.T) {
+	defer viper.Reset()
+
+	// every ctfd command has --url, the writeups one is bound last
+	flag := ctfdDownloadCmd.Flags().Lookup("url")
+	defer func() {
+		CheckErr(flag.Value.Set(flag.DefValue))
+		flag.Changed = false
+	}()
+
+	if err := ctfdDownloadCmd.ParseFlags([]string{"--url", "http://flag.example/"}); err != nil {
+		t.Fatalf("ParseFlags() returned error: %v", err)
+	}
+
+	// a profile merged into the config
+	if err := viper.MergeConfigMap(map[string]interface{}{"url": "http://profile.example/"}); err != nil {
+		t.Fatalf("MergeConfigMap() returned error: %v", err)
+	}
+
+	bindCommandFlags(ctfdDownloadCmd, nil)
+
+	if got := viper.GetString("url"); got != "http://flag.example/" {
+		t.Errorf("got url %q, want the flag of the running command to win over the profile", got)
+	}
+}
+
+func TestApplyProfile_DottedName(t *testing.T) {
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	config := "profiles:\n  my.ctf:\n    url: http://dotted.example/\n  other:\n    url: http://other.example/\n"
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatalf("ReadConfig() returned error: %v", err)
+	}
+
+	viper.Set("profile", "My.CTF")
+	if err := applyProfile(); err != nil {
+		t.Fatalf("applyProfile() returned error: %v", err)
+	}
+
+	if got := viper.GetString("url"); got != "http://dotted.example/" {
+		t.Errorf("got url %q, want the url of the my.ctf profile", got)
+	}
+
+	viper.Set("profile", "missing")
+	if err := applyProfile(); err == nil {
+		t.Error("applyProfile() returned no error for a missing profile")
+	}
+}
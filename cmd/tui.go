@@ -0,0 +1,635 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	tabChallenges = iota
+	tabScoreboard
+)
+
+const (
+	modeBrowse = iota
+	modeSubmit
+	modeUnlock
+)
+
+// tuiListWidth is the width of the challenge list, the detail pane takes the rest.
+const tuiListWidth = 40
+
+// tuiAPI serializes the requests of the TUI, the ctfd package uses a single client.
+var tuiAPI sync.Mutex
+
+var (
+	tuiTabStyle       = lipgloss.NewStyle().Padding(0, 1)
+	tuiActiveTabStyle = tuiTabStyle.Copy().Bold(true).Reverse(true)
+	tuiCursorStyle    = lipgloss.NewStyle().Bold(true).Reverse(true)
+	tuiCategoryStyle  = lipgloss.NewStyle().Bold(true).Underline(true)
+	tuiPaneStyle      = lipgloss.NewStyle().Border(lipgloss.NormalBorder(), false, false, false, true).PaddingLeft(1)
+	tuiErrorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse the challenges and the scoreboard of a CTFd instance",
+	Long: `Browse the challenges and the scoreboard of a CTFd instance in an
+interactive terminal UI.
+
+The challenges are listed by category, the selected challenge is shown next
+to the list. The scoreboard tab refreshes every --watch-interval.
+
+Keys:
+  ↑/k ↓/j   select a challenge
+  pgup pgdn scroll the challenge
+  tab       switch between challenges and scoreboard
+  d         download the challenge into the output directory
+  o         open the folder of the challenge
+  s         submit a flag
+  u         unlock the next locked hint
+  r         refresh
+  q         quit`,
+	Example: `  ctftool tui --url https://demo.ctfd.io --token abcdef12356 --output ./ctf`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := ctfd.NewClient()
+		ctfdOptions()
+		opts.Output = setupOutputFolder()
+
+		client.BaseURL = getBaseURL(cmd)
+		client.Creds = getCredentials(cmd)
+		client.MaxFileSize = opts.MaxFileSize
+
+		if !opts.SkipCTFDCheck {
+			CheckErr(ctfd.Check())
+		}
+
+		if (opts.Username != "" || opts.Password != "") && opts.Token == "" {
+			err := ctfd.Authenticate()
+			CheckErr(err)
+		}
+
+		model := newTUIModel(challengeLayout(cmd), opts.WatchInterval)
+
+		// log lines would break the screen, errors are shown in the status line
+		logOut := log.Out
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(logOut)
+
+		_, err := tea.NewProgram(model, tea.WithAltScreen()).Run()
+		CheckErr(err)
+	},
+}
+
+type (
+	challengesMsg struct {
+		challenges []ctfd.ChallengesData
+		err        error
+	}
+	detailMsg struct {
+		challenge *ctfd.ChallengeData
+		files     []ctfd.ChallengeFile
+		err       error
+	}
+	scoreboardMsg struct {
+		standings []ctfd.Standing
+		err       error
+	}
+	// actionMsg is the outcome of a download, submission or unlock. The
+	// challenge is requested again when reload is set.
+	actionMsg struct {
+		status string
+		err    error
+		reload int64
+	}
+	scoreboardTickMsg time.Time
+)
+
+type tuiModel struct {
+	layout     *ctfd.Layout
+	interval   time.Duration
+	challenges []ctfd.ChallengesData
+	plan       []plannedChallenge
+	cursor     int
+	details    map[int64]*ctfd.ChallengeData
+	files      map[int64][]ctfd.ChallengeFile
+	standings  []ctfd.Standing
+	updated    time.Time
+	tab        int
+	mode       int
+	input      textinput.Model
+	viewport   viewport.Model
+	status     string
+	err        error
+	width      int
+	height     int
+}
+
+func newTUIModel(layout *ctfd.Layout, interval time.Duration) *tuiModel {
+	input := textinput.New()
+	input.Placeholder = "flag{...}"
+	input.Prompt = "Flag: "
+
+	return &tuiModel{
+		layout:   layout,
+		interval: interval,
+		details:  make(map[int64]*ctfd.ChallengeData),
+		files:    make(map[int64][]ctfd.ChallengeFile),
+		input:    input,
+		viewport: viewport.New(0, 0),
+		status:   "Loading challenges...",
+	}
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return tea.Batch(loadChallenges, loadScoreboard, m.scoreboardTick())
+}
+
+func loadChallenges() tea.Msg {
+	tuiAPI.Lock()
+	defer tuiAPI.Unlock()
+
+	challenges, err := ctfd.ListChallenges()
+	return challengesMsg{challenges: challenges, err: err}
+}
+
+func loadScoreboard() tea.Msg {
+	tuiAPI.Lock()
+	defer tuiAPI.Unlock()
+
+	top, err := ctfd.ScoreboardTop(10)
+	return scoreboardMsg{standings: top.Standings(), err: err}
+}
+
+func loadChallenge(id int64) tea.Cmd {
+	return func() tea.Msg {
+		tuiAPI.Lock()
+		defer tuiAPI.Unlock()
+
+		challenge, err := ctfd.Challenge(id)
+		if err != nil {
+			return detailMsg{err: err}
+		}
+		return detailMsg{challenge: challenge, files: ctfd.ChallengeFiles(challenge)}
+	}
+}
+
+func (m *tuiModel) scoreboardTick() tea.Cmd {
+	if m.interval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg {
+		return scoreboardTickMsg(t)
+	})
+}
+
+// selected returns the selected challenge, or nil before the challenges are loaded.
+func (m *tuiModel) selected() *plannedChallenge {
+	if m.cursor < 0 || m.cursor >= len(m.plan) {
+		return nil
+	}
+	return &m.plan[m.cursor]
+}
+
+// selectChallenge loads the selected challenge unless it was loaded before.
+func (m *tuiModel) selectChallenge() tea.Cmd {
+	m.refreshDetail()
+
+	selected := m.selected()
+	if selected == nil {
+		return nil
+	}
+	if _, ok := m.details[selected.Challenge.ID]; ok {
+		return nil
+	}
+	return loadChallenge(selected.Challenge.ID)
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.viewport.Width = m.detailWidth()
+		m.viewport.Height = m.bodyHeight()
+		m.refreshDetail()
+		return m, nil
+
+	case challengesMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.setChallenges(msg.challenges)
+		m.status = fmt.Sprintf("%d challenges", len(m.plan))
+		return m, m.selectChallenge()
+
+	case detailMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.details[msg.challenge.ID] = msg.challenge
+		m.files[msg.challenge.ID] = msg.files
+		m.refreshDetail()
+		return m, nil
+
+	case scoreboardMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.standings, m.updated = msg.standings, time.Now()
+		return m, nil
+
+	case scoreboardTickMsg:
+		return m, tea.Batch(loadScoreboard, m.scoreboardTick())
+
+	case actionMsg:
+		m.status, m.err = msg.status, msg.err
+		if msg.reload == 0 {
+			return m, nil
+		}
+		delete(m.details, msg.reload)
+		return m, tea.Batch(loadChallenge(msg.reload), loadChallenges)
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case modeSubmit:
+			return m.updateSubmit(msg)
+		case modeUnlock:
+			return m.updateUnlock(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+
+	return m, nil
+}
+
+func (m *tuiModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab":
+		m.tab = (m.tab + 1) % 2
+		return m, nil
+	case "r":
+		m.status, m.err = "Refreshing...", nil
+		return m, tea.Batch(loadChallenges, loadScoreboard)
+	}
+
+	if m.tab != tabChallenges {
+		return m, nil
+	}
+
+	selected := m.selected()
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, m.selectChallenge()
+	case "down", "j":
+		if m.cursor < len(m.plan)-1 {
+			m.cursor++
+		}
+		return m, m.selectChallenge()
+	case "pgup", "pgdown":
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+
+	if selected == nil {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "d":
+		m.status, m.err = fmt.Sprintf("Downloading %s...", selected.Challenge.Name), nil
+		return m, m.download(*selected)
+	case "o":
+		if _, err := os.Stat(selected.Path); err != nil {
+			m.status, m.err = "", fmt.Errorf("%s is not downloaded yet, press d", selected.Challenge.Name)
+			return m, nil
+		}
+		m.status, m.err = fmt.Sprintf("Opened %s", selected.Path), openFolder(selected.Path)
+	case "s":
+		m.mode = modeSubmit
+		m.input.Reset()
+		return m, m.input.Focus()
+	case "u":
+		if hint := m.lockedHint(); hint != nil {
+			m.mode = modeUnlock
+			m.status, m.err = fmt.Sprintf("Unlock the hint for %d points? (y/n)", hint.Cost), nil
+		} else {
+			m.status, m.err = "No locked hint", nil
+		}
+	}
+
+	return m, nil
+}
+
+func (m *tuiModel) updateSubmit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.mode = modeBrowse
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		m.mode = modeBrowse
+		m.input.Blur()
+
+		flag := strings.TrimSpace(m.input.Value())
+		selected := m.selected()
+		if flag == "" || selected == nil {
+			return m, nil
+		}
+
+		m.status, m.err = "Submitting...", nil
+		return m, submitFlag(selected.Challenge.ID, flag)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *tuiModel) updateUnlock(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.mode = modeBrowse
+
+	hint := m.lockedHint()
+	if msg.String() != "y" || hint == nil {
+		m.status = "Unlock cancelled"
+		return m, nil
+	}
+
+	m.status = "Unlocking..."
+	return m, unlockHint(m.selected().Challenge.ID, hint.ID)
+}
+
+// lockedHint returns the first locked hint of the selected challenge.
+func (m *tuiModel) lockedHint() *ctfd.Hint {
+	selected := m.selected()
+	if selected == nil {
+		return nil
+	}
+
+	challenge, ok := m.details[selected.Challenge.ID]
+	if !ok {
+		return nil
+	}
+
+	for i := range challenge.Hints {
+		if challenge.Hints[i].Locked() {
+			return &challenge.Hints[i]
+		}
+	}
+	return nil
+}
+
+func (m *tuiModel) download(planned plannedChallenge) tea.Cmd {
+	challenges := m.challenges
+	layout := m.layout
+
+	return func() tea.Msg {
+		tuiAPI.Lock()
+		defer tuiAPI.Unlock()
+
+		if err := downloadChallenge(planned); err != nil {
+			return actionMsg{err: err}
+		}
+
+		if err := layout.Save(opts.Output, challenges); err != nil {
+			return actionMsg{err: err}
+		}
+
+		return actionMsg{status: fmt.Sprintf("Downloaded %s to %s", planned.Challenge.Name, planned.Path)}
+	}
+}
+
+func submitFlag(id int64, flag string) tea.Cmd {
+	return func() tea.Msg {
+		tuiAPI.Lock()
+		defer tuiAPI.Unlock()
+
+		if err := ctfd.SubmitFlag(ctfd.Submission{ID: int(id), Flag: flag}); err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{status: "Correct flag!", reload: id}
+	}
+}
+
+func unlockHint(challengeID int64, hintID int64) tea.Cmd {
+	return func() tea.Msg {
+		tuiAPI.Lock()
+		defer tuiAPI.Unlock()
+
+		if err := ctfd.UnlockHint(hintID); err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{status: "Unlocked the hint", reload: challengeID}
+	}
+}
+
+// setChallenges replaces the challenges, grouped by category and in the order
+// of download within a category. The selected challenge stays selected.
+func (m *tuiModel) setChallenges(challenges []ctfd.ChallengesData) {
+	var selectedID int64
+	if selected := m.selected(); selected != nil {
+		selectedID = selected.Challenge.ID
+	}
+
+	m.challenges = challenges
+	m.plan = planChallenges(challenges, m.layout, nil, downloadAction)
+	sort.SliceStable(m.plan, func(i, j int) bool {
+		return m.plan[i].Challenge.Category < m.plan[j].Challenge.Category
+	})
+
+	m.cursor = 0
+	for i, planned := range m.plan {
+		if planned.Challenge.ID == selectedID {
+			m.cursor = i
+		}
+	}
+}
+
+// refreshDetail renders the selected challenge into the detail pane.
+func (m *tuiModel) refreshDetail() {
+	selected := m.selected()
+	if selected == nil {
+		m.viewport.SetContent("")
+		return
+	}
+
+	challenge, ok := m.details[selected.Challenge.ID]
+	if !ok {
+		m.viewport.SetContent("Loading...")
+		return
+	}
+
+	m.viewport.SetContent(renderChallenge(challenge, m.files[challenge.ID], m.detailWidth()))
+	m.viewport.GotoTop()
+}
+
+func (m *tuiModel) detailWidth() int {
+	if width := m.width - tuiListWidth - 3; width > 20 {
+		return width
+	}
+	return 20
+}
+
+// bodyHeight is the height between the tabs and the status line.
+func (m *tuiModel) bodyHeight() int {
+	if height := m.height - 4; height > 1 {
+		return height
+	}
+	return 1
+}
+
+func (m *tuiModel) View() string {
+	var tabs []string
+	for i, name := range []string{"Challenges", "Scoreboard"} {
+		style := tuiTabStyle
+		if i == m.tab {
+			style = tuiActiveTabStyle
+		}
+		tabs = append(tabs, style.Render(name))
+	}
+
+	body := m.viewScoreboard()
+	if m.tab == tabChallenges {
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(tuiListWidth).Render(m.viewList()),
+			tuiPaneStyle.Render(m.viewport.View()),
+		)
+	}
+
+	status := m.status
+	switch {
+	case m.mode == modeSubmit:
+		status = m.input.View()
+	case m.err != nil:
+		status = tuiErrorStyle.Render(m.err.Error())
+	}
+
+	return fmt.Sprintf("%s\n\n%s\n%s", strings.Join(tabs, " "), body, status)
+}
+
+// viewList renders the challenges around the cursor, under their category.
+func (m *tuiModel) viewList() string {
+	var lines []string
+	cursorLine := 0
+	category := ""
+
+	for i, planned := range m.plan {
+		if planned.Challenge.Category != category || i == 0 {
+			category = planned.Challenge.Category
+			lines = append(lines, tuiCategoryStyle.Render(truncate(category, tuiListWidth)))
+		}
+
+		marker := "  "
+		if planned.Challenge.SolvedByMe {
+			marker = "✅"
+		}
+
+		line := truncate(fmt.Sprintf("%s %s (%d)", marker, planned.Challenge.Name, planned.Challenge.Value), tuiListWidth-1)
+		if i == m.cursor {
+			cursorLine = len(lines)
+			line = tuiCursorStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	// keep the cursor in view
+	height := m.bodyHeight()
+	start := 0
+	if cursorLine >= height {
+		start = cursorLine - height + 1
+	}
+	end := start + height
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	return strings.Join(lines[start:end], "\n")
+}
+
+func (m *tuiModel) viewScoreboard() string {
+	if len(m.standings) == 0 {
+		return "No scoreboard yet"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-6s %-40s %8s %7s\n", "RANK", "TEAM", "SCORE", "SOLVES")
+	for _, standing := range m.standings {
+		fmt.Fprintf(&b, "%-6d %-40s %8d %7d\n", standing.Rank, truncate(standing.Name, 40), standing.Score(), len(standing.Solves))
+	}
+	fmt.Fprintf(&b, "\nUpdated %s", m.updated.Format("15:04:05"))
+
+	return b.String()
+}
+
+// openFolder opens a folder in the file manager of the system.
+func openFolder(folder string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", folder)
+	case "windows":
+		cmd = exec.Command("explorer", folder)
+	default:
+		cmd = exec.Command("xdg-open", folder)
+	}
+
+	return cmd.Start()
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.Flags().StringVarP(&opts.URL, "url", "", "", "URL of the CTFd instance")
+	tuiCmd.Flags().StringVarP(&opts.Username, "username", "u", "", "Username for CTFd authentication")
+	tuiCmd.Flags().StringVarP(&opts.Password, "password", "p", "", "Password for CTFd authentication")
+	tuiCmd.Flags().StringVarP(&opts.Token, "token", "t", "", "Authentication token for CTFd")
+	tuiCmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Directory challenges are downloaded to (defaults to current directory)")
+	tuiCmd.Flags().DurationVarP(&opts.WatchInterval, "watch-interval", "", 5*time.Minute, "Interval for refreshing the scoreboard")
+	tuiCmd.Flags().Int64VarP(&opts.MaxFileSize, "max-file-size", "", 25, "Maximum allowable file size in MB")
+	tuiCmd.Flags().StringVarP(&opts.PathTemplate, "path-template", "", "", fmt.Sprintf("Folder of every challenge (default %q)", ctfd.DefaultPathTemplate))
+	tuiCmd.Flags().BoolVarP(&opts.SkipCTFDCheck, "skip-check", "", false, "Skip CTFd instance check")
+
+	// viper
+	err := viper.BindPFlag("url", tuiCmd.Flags().Lookup("url"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("username", tuiCmd.Flags().Lookup("username"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("password", tuiCmd.Flags().Lookup("password"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("token", tuiCmd.Flags().Lookup("token"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("output", tuiCmd.Flags().Lookup("output"))
+	CheckErr(err)
+
+	err = viper.BindPFlag("watch-interval", tuiCmd.Flags().Lookup("watch-interval"))
+	CheckErr(err)
+}
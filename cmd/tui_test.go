@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/go-cmp/cmp"
+	"github.com/ritchies/ctftool/pkg/ctfd"
+)
+
+func newTestTUIModel(t *testing.T) *tuiModel {
+	layout, err := ctfd.NewLayout("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newTUIModel(layout, time.Minute)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m.Update(challengesMsg{challenges: []ctfd.ChallengesData{
+		{ID: 1, Name: "Cookies", Category: "web", Value: 200, Solves: 10, SolvedByMe: true},
+		{ID: 2, Name: "Baby ROP", Category: "pwn", Value: 300},
+		{ID: 3, Name: "XSS me", Category: "web", Value: 100, Solves: 40},
+	}})
+
+	return m
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestTUIChallenges(t *testing.T) {
+	m := newTestTUIModel(t)
+
+	// grouped by category, in the order of download within a category
+	var ids []int64
+	for _, planned := range m.plan {
+		ids = append(ids, planned.Challenge.ID)
+	}
+	if want := []int64{2, 3, 1}; !cmp.Equal(ids, want) {
+		t.Errorf("got challenges %v, want %v", ids, want)
+	}
+
+	if _, cmd := m.Update(key("j")); cmd == nil {
+		t.Error("expected the selected challenge to be loaded")
+	}
+	if got := m.selected().Challenge.ID; got != 3 {
+		t.Errorf("got selected challenge %d, want 3", got)
+	}
+
+	m.Update(detailMsg{challenge: &ctfd.ChallengeData{ID: 3, Name: "XSS me", Description: "alert(1)", Hints: []ctfd.Hint{{ID: 9, Cost: 25}}}})
+
+	view := m.View()
+	for _, want := range []string{"pwn", "web", "✅ Cookies (200)", "alert(1)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the view:\n%s", want, view)
+		}
+	}
+
+	// a loaded challenge is not requested again
+	m.Update(key("k"))
+	if _, cmd := m.Update(key("j")); cmd != nil {
+		t.Error("expected the loaded challenge to be reused")
+	}
+
+	// unlocking a hint asks for confirmation
+	m.Update(key("u"))
+	if m.mode != modeUnlock || !strings.Contains(m.status, "25 points") {
+		t.Errorf("expected a confirmation, got mode %d and status %q", m.mode, m.status)
+	}
+	if _, cmd := m.Update(key("n")); cmd != nil || m.mode != modeBrowse {
+		t.Error("expected the unlock to be cancelled")
+	}
+
+	// the selection survives a refresh
+	m.Update(challengesMsg{challenges: []ctfd.ChallengesData{
+		{ID: 3, Name: "XSS me", Category: "web"},
+		{ID: 4, Name: "Aaa", Category: "crypto"},
+	}})
+	if got := m.selected().Challenge.ID; got != 3 {
+		t.Errorf("got selected challenge %d after a refresh, want 3", got)
+	}
+}
+
+func TestTUISubmitAndScoreboard(t *testing.T) {
+	m := newTestTUIModel(t)
+
+	m.Update(key("s"))
+	if m.mode != modeSubmit {
+		t.Fatal("expected the flag prompt")
+	}
+	m.Update(key("flag{x}"))
+	if _, cmd := m.Update(key("enter")); cmd == nil || m.mode != modeBrowse {
+		t.Error("expected the flag to be submitted")
+	}
+
+	m.Update(actionMsg{err: errors.New("incorrect")})
+	if !strings.Contains(m.View(), "incorrect") {
+		t.Errorf("expected the error in the status line:\n%s", m.View())
+	}
+
+	m.Update(key("tab"))
+	m.Update(scoreboardMsg{standings: []ctfd.Standing{{Rank: 1, Team: ctfd.Team{ID: 5, Name: "winners", Solves: []ctfd.Solves{{Value: 500}}}}}})
+	if view := m.View(); !strings.Contains(view, "winners") || !strings.Contains(view, "500") {
+		t.Errorf("expected the scoreboard:\n%s", view)
+	}
+
+	if _, cmd := m.Update(key("q")); cmd == nil {
+		t.Error("expected q to quit")
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"sort"
 	"strconv"
@@ -41,37 +42,63 @@ func init() {
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number",
-	Long:  `All software has versions. This is mine.`,
+	Long: `All software has versions. This is mine.
+
+The table output also checks GitHub for a newer release.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if Version == "dev" {
-			fmt.Printf("You are running a development build of ctftool\n")
-		} else {
-			fmt.Printf("ctftool %s (%s) built by %s on %s\n", Version, Commit, BuiltBy, Date)
-
-			// get the latest tag and hash
-			latest, hash := getLatestVersion()
-
-			// compare commit hashes
-			if strings.Contains(hash, Commit) {
-				fmt.Printf("You are on the latest version\n")
-				return
-			}
-
-			// compare the versions
-			if compareVersions(Version, latest) {
-				fmt.Printf("You are running an older version of ctftool\n")
-				fmt.Println()
-				fmt.Printf("Latest version: %s\n", latest)
-				fmt.Printf("Update using: go install -v github.com/ritchies/ctftool@latest\n")
-				// go install using the latest tag
-				fmt.Printf("Update using: go install -v github.com/ritchies/ctftool@%s\n", latest)
-			} else {
-				fmt.Printf("You are running a newer version of ctftool\n")
-			}
-		}
+		printResult(versionInfo{Version: Version, Commit: Commit, Date: Date, BuiltBy: BuiltBy})
 	},
 }
 
+// versionInfo is the output of version.
+type versionInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+	BuiltBy string `json:"built_by"`
+}
+
+func (v versionInfo) Columns() []string {
+	return []string{"version", "commit", "date", "built_by"}
+}
+
+func (v versionInfo) Rows() [][]string {
+	return [][]string{{v.Version, v.Commit, v.Date, v.BuiltBy}}
+}
+
+// Render prints the version and whether a newer release is available.
+func (v versionInfo) Render(w io.Writer) error {
+	if v.Version == "dev" {
+		fmt.Fprintf(w, "You are running a development build of ctftool\n")
+		return nil
+	}
+
+	fmt.Fprintf(w, "ctftool %s (%s) built by %s on %s\n", v.Version, v.Commit, v.BuiltBy, v.Date)
+
+	// get the latest tag and hash
+	latest, hash := getLatestVersion()
+
+	// compare commit hashes
+	if strings.Contains(hash, v.Commit) {
+		fmt.Fprintf(w, "You are on the latest version\n")
+		return nil
+	}
+
+	// compare the versions
+	if compareVersions(v.Version, latest) {
+		fmt.Fprintf(w, "You are running an older version of ctftool\n")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Latest version: %s\n", latest)
+		fmt.Fprintf(w, "Update using: go install -v github.com/ritchies/ctftool@latest\n")
+		// go install using the latest tag
+		fmt.Fprintf(w, "Update using: go install -v github.com/ritchies/ctftool@%s\n", latest)
+	} else {
+		fmt.Fprintf(w, "You are running a newer version of ctftool\n")
+	}
+
+	return nil
+}
+
 func compareVersions(a, b string) bool {
 	majorA, minorA, patchA := parseVersion(a)
 	majorB, minorB, patchB := parseVersion(b)
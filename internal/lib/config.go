@@ -0,0 +1,150 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the on-disk representation of a ctftool config file.
+// Unknown keys are preserved when the file is saved.
+type ConfigFile struct {
+	Path string
+	data map[string]interface{}
+}
+
+// Profile holds the settings of a named profile, keyed by flag name
+// (url, username, token, output, rate-limit, notify, ...).
+type Profile map[string]interface{}
+
+// LoadConfigFile reads the config file at the given path. A missing file is
+// not an error, an empty config is returned instead.
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	config := &ConfigFile{
+		Path: path,
+		data: make(map[string]interface{}),
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(b, &config.data); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %q: %v", path, err)
+	}
+
+	if config.data == nil {
+		config.data = make(map[string]interface{})
+	}
+
+	return config, nil
+}
+
+// Save writes the config file back to disk, creating the parent directory if
+// needed. The file is only readable by the current user since it may hold
+// credentials.
+func (c *ConfigFile) Save() error {
+	b, err := yaml.Marshal(c.data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.Path, b, 0o600)
+}
+
+// CurrentProfile returns the name of the profile selected with `profile use`.
+func (c *ConfigFile) CurrentProfile() string {
+	name, _ := c.data["current-profile"].(string)
+	return name
+}
+
+// SetCurrentProfile selects the profile used when no --profile flag is given.
+func (c *ConfigFile) SetCurrentProfile(name string) error {
+	if name == "" {
+		delete(c.data, "current-profile")
+		return nil
+	}
+
+	if _, ok := c.Profiles()[name]; !ok {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+
+	c.data["current-profile"] = name
+	return nil
+}
+
+// Profiles returns all the profiles stored in the config file.
+func (c *ConfigFile) Profiles() map[string]Profile {
+	profiles := make(map[string]Profile)
+
+	raw, _ := c.data["profiles"].(map[string]interface{})
+	for name, values := range raw {
+		profile := make(Profile)
+		if m, ok := values.(map[string]interface{}); ok {
+			for k, v := range m {
+				profile[k] = v
+			}
+		}
+		profiles[name] = profile
+	}
+
+	return profiles
+}
+
+// ProfileNames returns the sorted names of all profiles.
+func (c *ConfigFile) ProfileNames() []string {
+	var names []string
+	for name := range c.Profiles() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetProfile creates the named profile or merges the given values into it.
+func (c *ConfigFile) SetProfile(name string, values Profile) {
+	raw, ok := c.data["profiles"].(map[string]interface{})
+	if !ok {
+		raw = make(map[string]interface{})
+		c.data["profiles"] = raw
+	}
+
+	profile, ok := raw[name].(map[string]interface{})
+	if !ok {
+		profile = make(map[string]interface{})
+		raw[name] = profile
+	}
+
+	for k, v := range values {
+		profile[k] = v
+	}
+}
+
+// RemoveProfile deletes the named profile. If it was the current profile, the
+// selection is cleared as well.
+func (c *ConfigFile) RemoveProfile(name string) error {
+	raw, _ := c.data["profiles"].(map[string]interface{})
+	if _, ok := raw[name]; !ok {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+
+	delete(raw, name)
+
+	if c.CurrentProfile() == name {
+		delete(c.data, "current-profile")
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	config, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfigFile() returned error: %v", err)
+	}
+
+	if config.CurrentProfile() != "" || len(config.Profiles()) != 0 {
+		t.Errorf("expected an empty config, got %+v", config.Profiles())
+	}
+}
+
+func TestLoadConfigFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("profiles: [\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Error("expected an error for an invalid config file")
+	}
+}
+
+func TestConfigFileProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("rate-limit: 5\nprofiles:\n  work:\n    url: https://work.example\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() returned error: %v", err)
+	}
+
+	config.SetProfile("work", Profile{"username": "alice"})
+	config.SetProfile("home", Profile{"url": "https://home.example"})
+
+	if err := config.SetCurrentProfile("unknown"); err == nil {
+		t.Error("expected an error selecting an unknown profile")
+	}
+	if err := config.SetCurrentProfile("work"); err != nil {
+		t.Fatalf("SetCurrentProfile() returned error: %v", err)
+	}
+
+	if err := config.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("got permissions %o, want 600", perm)
+	}
+
+	config, err = LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() returned error: %v", err)
+	}
+
+	if got := config.ProfileNames(); !cmp.Equal(got, []string{"home", "work"}) {
+		t.Errorf("got profiles %v", got)
+	}
+
+	want := Profile{"url": "https://work.example", "username": "alice"}
+	if got := config.Profiles()["work"]; !cmp.Equal(got, want) {
+		t.Errorf("got profile %v, want merged values %v", got, want)
+	}
+
+	if config.CurrentProfile() != "work" {
+		t.Errorf("got current profile %q", config.CurrentProfile())
+	}
+
+	// unknown keys are kept
+	if config.data["rate-limit"] != 5 {
+		t.Errorf("expected rate-limit to be preserved, got %v", config.data["rate-limit"])
+	}
+
+	if err := config.RemoveProfile("work"); err != nil {
+		t.Fatalf("RemoveProfile() returned error: %v", err)
+	}
+	if config.CurrentProfile() != "" {
+		t.Error("expected removing the current profile to clear the selection")
+	}
+	if err := config.RemoveProfile("work"); err == nil {
+		t.Error("expected an error removing a missing profile")
+	}
+}
@@ -1,10 +1,34 @@
 package lib
 
+import "time"
+
 type Options struct {
 	ConfigFile  string
 	Debug       bool
 	DebugFormat string
-	RateLimit   int // rate limit per second
+	Profile     string // name of the active profile
+	RateLimit   int    // rate limit per second
+
+	// HTTP client options
+	Proxy         string   // http(s) or socks5 proxy URL
+	CACerts       []string // extra CA bundles
+	Insecure      bool     // skip TLS certificate verification
+	ClientCert    string   // client certificate for mTLS
+	ClientKey     string   // client key for mTLS
+	TLSMinVersion string   // minimum TLS version
+	UserAgent     string   // User-Agent header
+	Headers       []string // extra "Name: value" headers
+	Resolve       []string // host:port:addr resolution overrides
+	Record        string   // cassette file to record requests to
+	Replay        string   // cassette file to replay requests from
+	Cookie        string   // raw Cookie header to import
+	CookieFile    string   // Netscape cookie file to import
+
+	// HTTP cache options
+	Cache    bool          // cache responses on disk
+	CacheDir string        // directory of the cache
+	CacheTTL time.Duration // time a response is served without revalidation
+	Offline  bool          // only serve cached responses
 }
 
 // NewOptions returns a new Options struct
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"strings"
 
 	"github.com/ritchies/ctftool/pkg/scraper"
 )
@@ -66,7 +67,10 @@ func Authenticate() error {
 		return err
 	}
 
-	resp, err := scraper.FetchAndSubmitForm(client.Client, loginURL.String(), setPassword)
+	// Themes can have other forms (search, language) before the login form
+	resp, err := scraper.FetchAndSubmitFormWithOptions(client.Client, loginURL.String(), setPassword, &scraper.FormOptions{
+		Field: "password",
+	})
 	if err != nil {
 		return err
 	}
@@ -75,18 +79,31 @@ func Authenticate() error {
 		return fmt.Errorf("failed to authenticate: %s", resp.Status)
 	}
 
+	return checkAlerts(resp, "failed to authenticate")
+}
+
+// alertRegex matches the error alerts CTFd renders on its login, registration and team pages.
+var alertRegex = regexp.MustCompile(`<div class="alert alert-danger alert-dismissible text-center" role="alert">\s*<span>([^<]+)</span>`)
+
+// checkAlerts reads the page in the response and returns an error with the
+// messages of its error alerts, prefixed by the given message.
+func checkAlerts(resp *http.Response, message string) error {
 	html, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
 
-	errorRegex := regexp.MustCompile(`<div class="alert alert-danger alert-dismissible text-center" role="alert">\s*<span>([^<]+)</span>`)
-	if errorRegex.MatchString(string(html)) {
-		errorMessage := errorRegex.FindStringSubmatch(string(html))[1]
-		return fmt.Errorf("failed to authenticate: %s", errorMessage)
+	matches := alertRegex.FindAllStringSubmatch(string(html), -1)
+	if len(matches) == 0 {
+		return nil
 	}
 
-	return nil
+	var alerts []string
+	for _, match := range matches {
+		alerts = append(alerts, strings.TrimSpace(match[1]))
+	}
+
+	return fmt.Errorf("%s: %s", message, strings.Join(alerts, ", "))
 }
 
 // joinPath returns a URL string with the provided path elements joined to
@@ -104,3 +121,75 @@ func joinPath(base string, elements ...string) (*url.URL, error) {
 
 	return u, nil
 }
+
+// getData requests an API path and decodes the data of the response into
+// data, what names the requested data in errors.
+func getData(path string, what string, data interface{}) error {
+	response := struct {
+		Success bool        `json:"success"`
+		Data    interface{} `json:"data"`
+	}{Data: data}
+
+	resp, err := client.GetJson(path)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %v", what, err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", what, err)
+	}
+
+	if !response.Success {
+		return fmt.Errorf("failed to get %s from %q", what, resp.Request.URL)
+	}
+
+	return nil
+}
+
+// getPages requests every page of a paginated API path and gives the data of
+// each page to decode, what names the requested data in errors. Paths that
+// aren't paginated are requested once.
+func getPages(path string, what string, decode func(data json.RawMessage) error) error {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	for page := 1; ; {
+		response := new(struct {
+			Success bool            `json:"success"`
+			Data    json.RawMessage `json:"data"`
+			Meta    struct {
+				Pagination struct {
+					Next int `json:"next"`
+				} `json:"pagination"`
+			} `json:"meta"`
+		})
+
+		resp, err := client.GetJson(fmt.Sprintf("%s%spage=%d", path, separator, page))
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %v", what, err)
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(response)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %v", what, err)
+		}
+
+		if !response.Success {
+			return fmt.Errorf("failed to get %s from %q", what, resp.Request.URL)
+		}
+
+		if err := decode(response.Data); err != nil {
+			return fmt.Errorf("failed to decode %s: %v", what, err)
+		}
+
+		// next is null on the last page
+		if response.Meta.Pagination.Next <= page {
+			return nil
+		}
+		page = response.Meta.Pagination.Next
+	}
+}
@@ -17,7 +17,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ritchies/ctftool/internal/lib"
 	"github.com/ritchies/ctftool/pkg/scraper"
 	"golang.org/x/net/html"
 )
@@ -35,43 +34,46 @@ const (
 	maxRetries = 5
 )
 
-type Hint struct {
-	ID      int64  `json:"id"`
-	Cost    int64  `json:"cost"`
-	Content string `json:"content"`
+// TypeData describes the challenge type and the scripts used to render it.
+// Some plugins add the decay of dynamic challenges.
+type TypeData struct {
+	DynamicValue
+
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Templates struct {
+		Create string `json:"create"`
+		Update string `json:"update"`
+		View   string `json:"view"`
+	} `json:"templates"`
+	Scripts struct {
+		Create string `json:"create"`
+		Update string `json:"update"`
+		View   string `json:"view"`
+	} `json:"scripts"`
 }
 
 type ChallengeData struct {
-	ID             int64  `json:"id"`
-	Name           string `json:"name"`
-	Description    string `json:"description"`
-	ConnectionInfo string `json:"connection_info"`
-	NextID         int64  `json:"next_id"`
-	Attempts       int64  `json:"attempts"`
-	MaxAttempts    int64  `json:"max_attempts"`
-	Value          int64  `json:"value"`
-	Category       string `json:"category"`
-	Type           string `json:"type"`
-	TypeData       struct {
-		ID        string `json:"id"`
-		Name      string `json:"name"`
-		Templates struct {
-			Create string `json:"create"`
-			Update string `json:"update"`
-			View   string `json:"view"`
-		} `json:"templates"`
-		Scripts struct {
-			Create string `json:"create"`
-			Update string `json:"update"`
-			View   string `json:"view"`
-		} `json:"scripts"`
-	} `json:"type_data"`
-	State      string        `json:"state"`
-	Solves     int64         `json:"solves"`
-	SolvedByMe bool          `json:"solved_by_me"`
-	Files      []string      `json:"files"`
-	Hints      []Hint        `json:"hints"`
-	Tags       []interface{} `json:"tags"`
+	ID             int64    `json:"id"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	ConnectionInfo string   `json:"connection_info"`
+	NextID         int64    `json:"next_id"`
+	Attempts       int64    `json:"attempts"`
+	MaxAttempts    int64    `json:"max_attempts"`
+	Value          int64    `json:"value"`
+	Category       string   `json:"category"`
+	Type           string   `json:"type"`
+	TypeData       TypeData `json:"type_data"`
+	State          string   `json:"state"`
+	Solves         int64    `json:"solves"`
+	SolvedByMe     bool     `json:"solved_by_me"`
+	Files          FileList `json:"files"`
+	Hints          []Hint   `json:"hints"`
+	Tags           []Tag    `json:"tags"`
+
+	// the decay of dynamic challenges, empty for static ones
+	DynamicValue
 }
 
 // Challenge returns a challenge by ID
@@ -96,11 +98,14 @@ func Challenge(id int64) (*ChallengeData, error) {
 		return nil, fmt.Errorf("failed to get challenge from %q", resp.Request.URL)
 	}
 
+	normalizeChallenge(&response.Data)
+
 	return &response.Data, nil
 }
 
 // DownloadFiles will download all the files of a challenge by ID and save
-// them to the given directory
+// them to the given directory. Files are downloaded concurrently, within the
+// limit set by SetDownloadConcurrency.
 func DownloadFiles(files []string, outputPath string) error {
 	// if no files, return
 	if len(files) == 0 {
@@ -116,78 +121,142 @@ func DownloadFiles(files []string, outputPath string) error {
 		go func(file string) {
 			defer wg.Done()
 
-			var err error
-			var resp *http.Response
+			if err := downloadFile(file, outputPath); err != nil {
+				mu.Lock()
+				errors = append(errors, err)
+				mu.Unlock()
+			}
+		}(file)
+	}
 
-			for i := 0; i < maxRetries; i++ {
-				resp, err = client.GetFile(file)
-				if err != nil {
-					continue
-				}
+	wg.Wait()
 
-				if resp.StatusCode == http.StatusOK {
-					break
-				}
+	if len(errors) > 0 {
+		return fmt.Errorf("%d errors occurred while downloading files:\n%s", len(errors), formatErrors(errors))
+	}
 
-				resp.Body.Close()
-				time.Sleep(time.Second)
-			}
+	return nil
+}
 
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("failed to get file %q: %v", file, err))
-				mu.Unlock()
-				return
-			}
+// downloadFile downloads a single file into the directory, reporting its progress.
+func downloadFile(file string, outputPath string) (err error) {
+	release := acquireDownloadSlot()
+	defer release()
 
-			defer resp.Body.Close()
+	progress := downloadProgress()
+	name := file
 
-			fileName, err := getFileName(resp.Request.URL.String())
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("failed to get file name: %v", err))
-				mu.Unlock()
-				return
-			}
+	var resp *http.Response
+	for i := 0; i < maxRetries; i++ {
+		resp, err = client.GetFile(file)
+		if err != nil {
+			continue
+		}
 
-			if resp.ContentLength > (client.MaxFileSize*OneMB) || resp.ContentLength <= 0 {
-				sizeInMegaBytes := resp.ContentLength / OneMB
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("file %q is too large (%d/%d MB)", fileName, sizeInMegaBytes, client.MaxFileSize))
-				mu.Unlock()
-				return
-			}
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
 
-			filePath := path.Join(outputPath, fileName)
-			f, err := os.Create(filePath)
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("failed to create file %q: %v", filePath, err))
-				mu.Unlock()
-				return
-			}
+		resp.Body.Close()
+		err = fmt.Errorf("received status code %d", resp.StatusCode)
+		time.Sleep(time.Second)
+	}
 
-			defer f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to get file %q: %v", file, err)
+	}
 
-			_, err = io.Copy(f, resp.Body)
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("failed to write file %q: %v", filePath, err))
-				mu.Unlock()
-				return
-			}
-		}(file)
+	defer resp.Body.Close()
+
+	fileName, err := getFileName(resp.Request.URL.String())
+	if err != nil {
+		return fmt.Errorf("failed to get file name: %v", err)
 	}
+	name = path.Join(path.Base(outputPath), fileName)
 
-	wg.Wait()
+	progress.Start(name, resp.ContentLength)
+	defer func() { progress.Done(name, err) }()
 
-	if len(errors) > 0 {
-		return fmt.Errorf("%d errors occurred while downloading files:\n%s", len(errors), formatErrors(errors))
+	if resp.ContentLength > (client.MaxFileSize*OneMB) || resp.ContentLength <= 0 {
+		sizeInMegaBytes := resp.ContentLength / OneMB
+		return fmt.Errorf("file %q is too large (%d/%d MB)", fileName, sizeInMegaBytes, client.MaxFileSize)
+	}
+
+	filePath := path.Join(outputPath, fileName)
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %v", filePath, err)
+	}
+
+	defer f.Close()
+
+	_, err = io.Copy(f, io.TeeReader(resp.Body, &progressWriter{progress: progress, name: name}))
+	if err != nil {
+		return fmt.Errorf("failed to write file %q: %v", filePath, err)
 	}
 
 	return nil
 }
 
+// ChallengeFile is a file of a challenge, as listed by ChallengeFiles.
+type ChallengeFile struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Size int64  `json:"size"` // -1 when the server does not announce it
+}
+
+// ChallengeFiles returns the name, URL and size of the files of a challenge.
+// The sizes are requested with HEAD requests, without downloading the files.
+func ChallengeFiles(challenge *ChallengeData) []ChallengeFile {
+	files := make([]ChallengeFile, 0, len(challenge.Files))
+
+	for _, challengeFile := range challenge.Files {
+		file := ChallengeFile{Name: challengeFile, Size: -1}
+
+		if name, err := getFileName(challengeFile); err == nil {
+			file.Name = name
+		}
+
+		fileURL, err := client.BaseURL.Parse(challengeFile)
+		if err != nil {
+			files = append(files, file)
+			continue
+		}
+		file.URL = fileURL.String()
+
+		file.Size = fileSize(file.URL)
+
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// fileSize requests the size of a file once, without the retries of DoRequest,
+// so a server refusing HEAD requests doesn't slow it down. It is -1 when unknown.
+func fileSize(fileURL string) int64 {
+	req, err := http.NewRequest(http.MethodHead, fileURL, nil)
+	if err != nil {
+		return -1
+	}
+
+	if client.Creds != nil && client.Creds.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", client.Creds.Token))
+	}
+
+	resp, err := client.Client.Do(req)
+	if err != nil {
+		return -1
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return -1
+	}
+
+	return resp.ContentLength
+}
+
 // GetDescription retrieves a challenge and returns a writeup template of the challenge
 func GetDescription(challenge *ChallengeData, challengePath string) error {
 	challengePath = path.Join(challengePath, "README.md")
@@ -252,7 +321,7 @@ func GetDescription(challenge *ChallengeData, challengePath string) error {
 			return fmt.Errorf("error writing to file: %v", err)
 		}
 		for _, tag := range challenge.Tags {
-			_, err = file.WriteString(fmt.Sprintf("- %s\n", tag))
+			_, err = file.WriteString(fmt.Sprintf("- %s\n", tag.Value))
 			if err != nil {
 				return fmt.Errorf("error writing to file: %v", err)
 			}
@@ -296,70 +365,12 @@ func GetDescription(challenge *ChallengeData, challengePath string) error {
 		}
 	}
 
-	// description
-	cleanDescription := func(desc string) string {
-		parser := strings.NewReader(desc)
-		decoder := html.NewTokenizer(parser)
-		var text string
-		for {
-			tt := decoder.Next()
-			switch tt {
-			case html.ErrorToken:
-				return text
-
-			// img tags
-			case html.SelfClosingTagToken:
-				token := decoder.Token()
-				if token.Data == "img" {
-					text += fmt.Sprintf("![%s](%s)\n", token.String(), token.Attr[0].Val)
-				}
-
-			case html.StartTagToken:
-				token := decoder.Token()
-
-				// img tags
-				if token.Data == "img" {
-					fileName, err := getFileName(token.Attr[0].Val)
-					if err != nil {
-						return text
-					}
-
-					text += fmt.Sprintf("![%s](%s)\n", fileName, token.Attr[0].Val)
-				}
-
-				// code block
-				if token.Data == "code" {
-					text += "`"
-				}
-			case html.EndTagToken:
-				token := decoder.Token()
-
-				// code block
-				if token.Data == "code" {
-					text += "`"
-				}
-
-			case html.TextToken:
-				text += decoder.Token().String()
-			}
-		}
-
-	}(challenge.Description)
-
 	_, err = file.WriteString("## Description\n\n")
 	if err != nil {
 		return fmt.Errorf("error writing to file: %v", err)
 	}
 
-	// trip leading and trailing newlines
-	description := strings.TrimSpace(cleanDescription)
-
-	// replace multiple newlines and \r\n
-	newlineRegex := regexp.MustCompile(`\n{2,}|\r\n`)
-	description = newlineRegex.ReplaceAllString(description, "\n\n")
-
-	// remove html entities
-	description = html.UnescapeString(description)
+	description := DescriptionText(challenge.Description)
 
 	_, err = file.WriteString(fmt.Sprintf("%s\n", description))
 	if err != nil {
@@ -367,13 +378,13 @@ func GetDescription(challenge *ChallengeData, challengePath string) error {
 	}
 
 	// hints (if available)
-	if len(challenge.Hints) > 0 && challenge.Hints[0].Content != "" {
+	if len(challenge.Hints) > 0 && !challenge.Hints[0].Locked() {
 		_, err = file.WriteString("## Hints\n")
 		if err != nil {
 			return fmt.Errorf("error writing to file: %v", err)
 		}
 		for _, hint := range challenge.Hints {
-			if hint.Content != "" {
+			if !hint.Locked() {
 				_, err = file.WriteString(fmt.Sprintf("- %s\n", hint.Content))
 				if err != nil {
 					return fmt.Errorf("error writing to file: %v", err)
@@ -403,69 +414,67 @@ func GetDescription(challenge *ChallengeData, challengePath string) error {
 	return nil
 }
 
-// GenerateIndex generates an index.md file with a list of all challenges in their respective categories
-func GenerateIndex(challenges []ChallengesData, outputPath string) error {
-	file, err := os.Create(path.Join(outputPath, "index.md"))
-	if err != nil {
-		return fmt.Errorf("error creating file: %v", err)
-	}
-	defer file.Close()
+// DescriptionText converts the HTML description of a challenge to text,
+// keeping images as markdown images and inline code in backticks.
+func DescriptionText(description string) string {
+	// trip leading and trailing newlines
+	text := strings.TrimSpace(descriptionTokens(description))
 
-	_, err = file.WriteString("# Index\n\n")
-	if err != nil {
-		return fmt.Errorf("error writing to file: %v", err)
-	}
+	// replace multiple newlines and \r\n
+	newlineRegex := regexp.MustCompile(`\n{2,}|\r\n`)
+	text = newlineRegex.ReplaceAllString(text, "\n\n")
 
-	// get all the categories
-	categories := make(map[string][]ChallengesData)
-	for _, challenge := range challenges {
-		category := strings.Split(challenge.Category, " ")[0]
-		category = lib.CleanSlug(category, true)
+	// remove html entities
+	return html.UnescapeString(text)
+}
 
-		categories[category] = append(categories[category], challenge)
-	}
+// descriptionTokens extracts the text, images and inline code of an HTML description.
+func descriptionTokens(desc string) string {
+	parser := strings.NewReader(desc)
+	decoder := html.NewTokenizer(parser)
+	var text string
+	for {
+		tt := decoder.Next()
+		switch tt {
+		case html.ErrorToken:
+			return text
+
+		// img tags
+		case html.SelfClosingTagToken:
+			token := decoder.Token()
+			if token.Data == "img" {
+				text += fmt.Sprintf("![%s](%s)\n", token.String(), token.Attr[0].Val)
+			}
 
-	// Sort categories alphabetically
-	var sortedCategories []string
-	for category := range categories {
-		sortedCategories = append(sortedCategories, category)
-	}
-	sort.Strings(sortedCategories)
+		case html.StartTagToken:
+			token := decoder.Token()
 
-	// write the categories
-	for _, category := range sortedCategories {
-		_, err = file.WriteString(fmt.Sprintf("## %s\n\n", strings.ToUpper(category)))
-		if err != nil {
-			return fmt.Errorf("error writing to file: %v", err)
-		}
+			// img tags
+			if token.Data == "img" {
+				fileName, err := getFileName(token.Attr[0].Val)
+				if err != nil {
+					return text
+				}
 
-		// Sort challenges within each category alphabetically
-		sort.Slice(categories[category], func(i, j int) bool {
-			return categories[category][i].Name < categories[category][j].Name
-		})
-
-		for _, challenge := range categories[category] {
-			var solved string
-			// Check if solved by me
-			if challenge.SolvedByMe {
-				solved = "✅"
-			} else {
-				solved = "❌"
+				text += fmt.Sprintf("![%s](%s)\n", fileName, token.Attr[0].Val)
 			}
 
-			_, err = file.WriteString(fmt.Sprintf("- %s [%s](%s/%s/README.md)\n", solved, challenge.Name, category, lib.CleanSlug(challenge.Name, false)))
-			if err != nil {
-				return fmt.Errorf("error writing to file: %v", err)
+			// code block
+			if token.Data == "code" {
+				text += "`"
 			}
-		}
+		case html.EndTagToken:
+			token := decoder.Token()
 
-		_, err = file.WriteString("\n")
-		if err != nil {
-			return fmt.Errorf("error writing to file: %v", err)
+			// code block
+			if token.Data == "code" {
+				text += "`"
+			}
+
+		case html.TextToken:
+			text += decoder.Token().String()
 		}
 	}
-
-	return nil
 }
 
 type Submission struct {
@@ -482,20 +491,28 @@ func SubmitFlag(submission Submission) error {
 
 	nonce := scraper.ExtractCSRF(resp)
 
-	client.BaseURL, _ = client.BaseURL.Parse("api/v1/challenges/attempt")
+	// the client is shared by long-lived sessions like the TUI, its base URL
+	// must stay the same whatever the outcome of the submission
+	attemptURL, err := client.BaseURL.Parse("api/v1/challenges/attempt")
+	if err != nil {
+		return err
+	}
 
 	data, err := json.Marshal(submission)
 	if err != nil {
 		return fmt.Errorf("failed to marshal submission: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", client.BaseURL.String(), bytes.NewBuffer(data))
+	req, err := http.NewRequest("POST", attemptURL.String(), bytes.NewBuffer(data))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
 	}
 
 	req.Header.Set("Csrf-Token", nonce)
 	req.Header.Set("Content-Type", "application/json")
+	if client.Creds != nil && client.Creds.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", client.Creds.Token))
+	}
 
 	resp, err = client.Client.Do(req)
 	if err != nil {
@@ -529,7 +546,7 @@ func SubmitFlag(submission Submission) error {
 		return fmt.Errorf("failed to submit flag: %s", response.Data.Message)
 	}
 
-	client.BaseURL.Path = ""
+	forgetSolves()
 
 	// check the challenge id and if we actually solved it
 	challenge, err := Challenge(int64(submission.ID))
@@ -544,6 +561,65 @@ func SubmitFlag(submission Submission) error {
 	return nil
 }
 
+// UnlockHint unlocks a hint, spending its cost in points. The challenge has
+// to be requested again to see the content of the hint.
+func UnlockHint(id int64) error {
+	resp, err := client.GetJson("challenges")
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	nonce := scraper.ExtractCSRF(resp)
+
+	unlockURL, err := client.BaseURL.Parse("api/v1/unlocks")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"target": id, "type": "hints"})
+	if err != nil {
+		return fmt.Errorf("failed to marshal unlock: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", unlockURL.String(), bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Csrf-Token", nonce)
+	req.Header.Set("Content-Type", "application/json")
+	if client.Creds != nil && client.Creds.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", client.Creds.Token))
+	}
+
+	resp, err = client.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	response := new(struct {
+		Success bool                `json:"success"`
+		Errors  map[string][]string `json:"errors"`
+	})
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("received status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	if !response.Success {
+		var messages []string
+		for _, errs := range response.Errors {
+			messages = append(messages, errs...)
+		}
+		sort.Strings(messages)
+		return fmt.Errorf("failed to unlock hint %d: %s", id, strings.Join(messages, ", "))
+	}
+
+	return nil
+}
+
 // getFileName takes in a URL path string, splits it by '/' and returns the last element of the split which is expected to be the file name.
 // It also handles the case where there is a query parameter by splitting the file name again by '?' and returning only the first element which is the file name.
 //
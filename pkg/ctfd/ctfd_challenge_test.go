@@ -3,6 +3,7 @@ package ctfd
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -34,7 +35,7 @@ func TestGetChallenge(t *testing.T) {
 				Content: "test hint",
 			},
 		},
-		Tags: []interface{}{"test tag"},
+		Tags: []Tag{{Value: "test tag"}},
 	}
 
 	// setup mux
@@ -168,7 +169,7 @@ func TestGetChallenge(t *testing.T) {
 				Content: "test hint",
 			},
 		},
-		Tags: []interface{}{"test tag"},
+		Tags: []Tag{{Value: "test tag"}},
 	}
 
 	mux.HandleFunc("/api/v1/challenges/2", func(w http.ResponseWriter, r *http.Request) {
@@ -232,3 +233,142 @@ func TestGetFileName(t *testing.T) {
 		}
 	}
 }
+
+func TestChallengeFiles(t *testing.T) {
+	_, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/files/abc/app.zip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("expected a HEAD request, got %s", r.Method)
+		}
+		w.Header().Set("Content-Length", "1234")
+	})
+
+	challenge := &ChallengeData{Files: FileList{"/files/abc/app.zip?token=xyz", "/files/def/missing.txt"}}
+
+	files := ChallengeFiles(challenge)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %+v", files)
+	}
+
+	if files[0].Name != "app.zip" || files[0].Size != 1234 {
+		t.Errorf("got %+v, want app.zip of 1234 bytes", files[0])
+	}
+
+	if files[1].Name != "missing.txt" || files[1].Size != -1 {
+		t.Errorf("got %+v, want missing.txt of unknown size", files[1])
+	}
+}
+
+func TestDescriptionText(t *testing.T) {
+	tests := []struct {
+		description string
+		html        string
+		want        string
+	}{
+		{"plain text", "Find the flag", "Find the flag"},
+		{"inline code", "<p>Run <code>nc host 1337</code></p>", "Run `nc host 1337`"},
+		{"entities and newlines", "\n\n<p>a &amp; b</p>\n\n\n<p>c</p>\n", "a & b\n\nc"},
+		{"image", `<img src="/files/abc/cat.png">`, "![cat.png](/files/abc/cat.png)"},
+	}
+
+	for _, test := range tests {
+		if got := DescriptionText(test.html); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.description, got, test.want)
+		}
+	}
+}
+
+func TestUnlockHint(t *testing.T) {
+	_, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/challenges", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><script>var csrf_nonce = "abc";</script></html>`))
+	})
+
+	var unlocked []int64
+	mux.HandleFunc("/api/v1/unlocks", func(w http.ResponseWriter, r *http.Request) {
+		var unlock struct {
+			Target int64  `json:"target"`
+			Type   string `json:"type"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&unlock); err != nil || unlock.Type != "hints" {
+			t.Errorf("unexpected unlock %+v: %v", unlock, err)
+		}
+
+		if unlock.Target == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"success": false, "errors": {"score": ["You do not have enough points to unlock this hint"]}}`))
+			return
+		}
+
+		unlocked = append(unlocked, unlock.Target)
+		w.Write([]byte(`{"success": true, "data": {"id": 1, "target": 1, "type": "hints"}}`))
+	})
+
+	if err := UnlockHint(1); err != nil {
+		t.Errorf("UnlockHint(1) returned error: %v", err)
+	}
+
+	err := UnlockHint(2)
+	if err == nil || !strings.Contains(err.Error(), "not have enough points") {
+		t.Errorf("expected the reason of the failure, got %v", err)
+	}
+
+	if len(unlocked) != 1 || unlocked[0] != 1 {
+		t.Errorf("got unlocked hints %v, want [1]", unlocked)
+	}
+}
+
+func TestSubmitFlag_KeepsBaseURL(t *testing.T) {
+	client, mux, cleanup := setup()
+	defer cleanup()
+
+	// an instance hosted under a path
+	client.BaseURL, _ = client.BaseURL.Parse("ctf/")
+	base := client.BaseURL.String()
+
+	mux.HandleFunc("/ctf/challenges", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><script>var csrf_nonce = "abc";</script></html>`))
+	})
+	mux.HandleFunc("/ctf/api/v1/challenges/attempt", func(w http.ResponseWriter, r *http.Request) {
+		var submission Submission
+		json.NewDecoder(r.Body).Decode(&submission)
+
+		switch submission.Flag {
+		case "fast":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"success": true, "data": {"status": "ratelimited", "message": "You're submitting flags too fast. Slow down."}}`))
+		case "wrong":
+			w.Write([]byte(`{"success": false, "data": {"status": "incorrect", "message": "Incorrect"}}`))
+		default:
+			w.Write([]byte(`{"success": true, "data": {"status": "correct", "message": "Correct"}}`))
+		}
+	})
+	mux.HandleFunc("/ctf/api/v1/challenges/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "data": {"id": 1, "name": "warmup", "solved_by_me": true}}`))
+	})
+
+	tests := []struct {
+		description string
+		flag        string
+		wantErr     bool
+	}{
+		{description: "rate limited", flag: "fast", wantErr: true},
+		{description: "incorrect", flag: "wrong", wantErr: true},
+		{description: "correct", flag: "flag{ok}"},
+	}
+
+	for _, test := range tests {
+		err := SubmitFlag(Submission{ID: 1, Flag: test.flag})
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: got error %v, want error %v", test.description, err, test.wantErr)
+		}
+
+		if got := client.BaseURL.String(); got != base {
+			t.Errorf("%s: base URL changed to %q, want %q", test.description, got, base)
+		}
+	}
+}
@@ -6,14 +6,14 @@ import (
 )
 
 type ChallengesData struct {
-	ID         int64         `json:"id"`
-	Type       string        `json:"type"`
-	Name       string        `json:"name"`
-	Value      int64         `json:"value"`
-	Solves     int64         `json:"solves"`
-	SolvedByMe bool          `json:"solved_by_me"`
-	Category   string        `json:"category"`
-	Tags       []interface{} `json:"tags"`
+	ID         int64  `json:"id"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Value      int64  `json:"value"`
+	Solves     int64  `json:"solves"`
+	SolvedByMe bool   `json:"solved_by_me"`
+	Category   string `json:"category"`
+	Tags       []Tag  `json:"tags"`
 }
 
 // ListChallenges returns a list of challenges
@@ -37,5 +37,8 @@ func ListChallenges() ([]ChallengesData, error) {
 	if !response.Success {
 		return nil, fmt.Errorf("failed to get challenges from %q", resp.Request.URL)
 	}
+
+	normalizeChallenges(response.Data)
+
 	return response.Data, nil
 }
@@ -0,0 +1,256 @@
+package ctfd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// This file isolates the differences between CTFd versions. The API responses
+// are decoded into the same typed structs for every version, the rest of the
+// package should not have to know which version it talks to.
+
+// Tag is a challenge tag. CTFd returns tags as {"value": "..."} objects, some
+// 2.x releases and plugins return plain strings.
+type Tag struct {
+	Value string `json:"value"`
+}
+
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		t.Value = value
+		return nil
+	}
+
+	var tag struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &tag); err != nil {
+		return fmt.Errorf("unexpected tag %s", data)
+	}
+
+	t.Value = tag.Value
+	return nil
+}
+
+func (t Tag) String() string {
+	return t.Value
+}
+
+// Hint is a challenge hint. Locked hints have no content, some 2.x releases
+// return unlocked hints as plain strings.
+type Hint struct {
+	ID      int64  `json:"id"`
+	Cost    int64  `json:"cost"`
+	Content string `json:"content"`
+}
+
+func (h *Hint) UnmarshalJSON(data []byte) error {
+	var content string
+	if err := json.Unmarshal(data, &content); err == nil {
+		*h = Hint{Content: content}
+		return nil
+	}
+
+	type hint Hint
+	if err := json.Unmarshal(data, (*hint)(h)); err != nil {
+		return fmt.Errorf("unexpected hint %s", data)
+	}
+
+	return nil
+}
+
+// Locked reports whether the hint has to be unlocked before its content is visible.
+func (h Hint) Locked() bool {
+	return h.Content == ""
+}
+
+// FileList holds the download paths of the files of a challenge. CTFd 3 adds
+// a ?token= to every path, so files can be downloaded without a session, 2.x
+// does not. Plugins can return {"location": "..."} objects instead of strings.
+type FileList []string
+
+func (f *FileList) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("unexpected files %s", data)
+	}
+
+	files := make(FileList, 0, len(items))
+	for _, item := range items {
+		var location string
+		if err := json.Unmarshal(item, &location); err != nil {
+			var file struct {
+				Location string `json:"location"`
+				URL      string `json:"url"`
+			}
+			if err := json.Unmarshal(item, &file); err != nil {
+				return fmt.Errorf("unexpected file %s", item)
+			}
+
+			location = file.Location
+			if location == "" {
+				location = file.URL
+			}
+		}
+
+		if location == "" {
+			continue
+		}
+
+		files = append(files, location)
+	}
+
+	*f = files
+	return nil
+}
+
+var (
+	versionsMu sync.Mutex
+	// versions caches the fingerprinted version of every instance
+	versions = make(map[string]*ServerVersion)
+)
+
+// serverVersion returns the version of the instance of the client. It is
+// fingerprinted once per instance, an unknown version is treated like the
+// latest one.
+func serverVersion() *ServerVersion {
+	versionsMu.Lock()
+	defer versionsMu.Unlock()
+
+	key := client.BaseURL.String()
+	if version, ok := versions[key]; ok {
+		return version
+	}
+
+	version, err := DetectVersion()
+	if err != nil {
+		version = &ServerVersion{}
+	}
+
+	versions[key] = version
+	return version
+}
+
+var (
+	solvesMu sync.Mutex
+	// solves caches the solved challenges of every instance, so a CTFd 2
+	// challenge doesn't request the solves of the account again
+	solves = make(map[string]map[int64]bool)
+)
+
+// normalizeChallenges fills in the fields the challenge list of older versions lacks.
+func normalizeChallenges(challenges []ChallengesData) {
+	version := serverVersion()
+	if version.Major != 2 {
+		return
+	}
+
+	// CTFd 2 has no solved_by_me, the solves of the user or team are listed
+	// instead. Listing the challenges refreshes the solved challenges.
+	solved := solvedChallenges(version)
+
+	solvesMu.Lock()
+	solves[client.BaseURL.String()] = solved
+	solvesMu.Unlock()
+
+	for i := range challenges {
+		if solved[challenges[i].ID] {
+			challenges[i].SolvedByMe = true
+		}
+	}
+}
+
+// normalizeChallenge fills in the fields the challenge of older versions lacks.
+// The solved challenges are requested once per instance and reused.
+func normalizeChallenge(challenge *ChallengeData) {
+	version := serverVersion()
+	if version.Major != 2 || challenge.SolvedByMe {
+		return
+	}
+
+	solvesMu.Lock()
+	defer solvesMu.Unlock()
+
+	key := client.BaseURL.String()
+	solved, ok := solves[key]
+	if !ok {
+		solved = solvedChallenges(version)
+		solves[key] = solved
+	}
+
+	challenge.SolvedByMe = solved[challenge.ID]
+}
+
+// forgetSolves drops the cached solved challenges of the instance, after a
+// submission may have solved one.
+func forgetSolves() {
+	solvesMu.Lock()
+	defer solvesMu.Unlock()
+
+	delete(solves, client.BaseURL.String())
+}
+
+// solvedChallenges returns the IDs of the challenges solved by the user, or
+// by the team in team mode. Errors are ignored, they only mean nothing is solved yet.
+func solvedChallenges(version *ServerVersion) map[int64]bool {
+	account := "users"
+	if version.UserMode == "teams" {
+		account = "teams"
+	}
+
+	solved := make(map[int64]bool)
+
+	resp, err := getOnce(fmt.Sprintf("api/v1/%s/me/solves", account))
+	if err != nil {
+		return solved
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return solved
+	}
+
+	response := new(struct {
+		Success bool `json:"success"`
+		Data    []struct {
+			ChallengeID int64 `json:"challenge_id"`
+		} `json:"data"`
+	})
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return solved
+	}
+
+	for _, solve := range response.Data {
+		solved[solve.ChallengeID] = true
+	}
+
+	return solved
+}
+
+// getOnce requests a path of the instance without the retries of DoRequest,
+// for probes where an error status is an answer rather than a failure.
+func getOnce(path string) (*http.Response, error) {
+	u, err := client.BaseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Creds != nil && client.Creds.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", client.Creds.Token))
+	}
+	if strings.HasPrefix(path, "api/") {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return client.Client.Do(req)
+}
@@ -0,0 +1,150 @@
+package ctfd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChallengeDataCompat(t *testing.T) {
+	tests := []struct {
+		description string
+		json        string
+		tags        []Tag
+		hints       []Hint
+		files       FileList
+	}{
+		{
+			"ctfd 3",
+			`{"tags": [{"value": "web"}], "hints": [{"id": 1, "cost": 0, "content": "look closer"}, {"id": 2, "cost": 50}], "files": ["/files/abc/app.zip?token=xyz"]}`,
+			[]Tag{{Value: "web"}},
+			[]Hint{{ID: 1, Content: "look closer"}, {ID: 2, Cost: 50}},
+			FileList{"/files/abc/app.zip?token=xyz"},
+		},
+		{
+			"ctfd 2 with plain strings",
+			`{"tags": ["crypto"], "hints": ["try rot13"], "files": ["/files/abc/app.zip"]}`,
+			[]Tag{{Value: "crypto"}},
+			[]Hint{{Content: "try rot13"}},
+			FileList{"/files/abc/app.zip"},
+		},
+		{
+			"plugin file objects",
+			`{"files": [{"location": "/files/abc/a.zip"}, {"url": "https://cdn.example.com/b.zip"}, {}]}`,
+			nil,
+			nil,
+			FileList{"/files/abc/a.zip", "https://cdn.example.com/b.zip"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var challenge ChallengeData
+			if err := json.Unmarshal([]byte(test.json), &challenge); err != nil {
+				t.Fatalf("failed to decode challenge: %v", err)
+			}
+
+			if !cmp.Equal(challenge.Tags, test.tags) {
+				t.Errorf("tags: got %+v, want %+v", challenge.Tags, test.tags)
+			}
+
+			if !cmp.Equal(challenge.Hints, test.hints) {
+				t.Errorf("hints: got %+v, want %+v", challenge.Hints, test.hints)
+			}
+
+			if !cmp.Equal(challenge.Files, test.files) {
+				t.Errorf("files: got %+v, want %+v", challenge.Files, test.files)
+			}
+		})
+	}
+
+	var tag Tag
+	if err := json.Unmarshal([]byte(`42`), &tag); err == nil {
+		t.Error("expected an error for an unexpected tag")
+	}
+}
+
+func TestListChallengesCTFd2(t *testing.T) {
+	_, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><script>var script_root = ""; var csrf_nonce = "abc"; var user_mode = "teams";</script></html>`)
+	})
+
+	mux.HandleFunc("/api/v1/challenges", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "data": [{"id": 1, "name": "a", "tags": ["web"]}, {"id": 2, "name": "b", "tags": []}]}`)
+	})
+
+	mux.HandleFunc("/api/v1/teams/me/solves", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "data": [{"challenge_id": 2}]}`)
+	})
+
+	challenges, err := ListChallenges()
+	if err != nil {
+		t.Fatalf("ListChallenges() returned error: %v", err)
+	}
+
+	got := make(map[int64]bool)
+	for _, challenge := range challenges {
+		got[challenge.ID] = challenge.SolvedByMe
+	}
+
+	if want := map[int64]bool{1: false, 2: true}; !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if challenges[0].Tags[0].Value != "web" {
+		t.Errorf("expected tag %q, got %+v", "web", challenges[0].Tags)
+	}
+}
+
+func TestChallengeCTFd2ReusesSolves(t *testing.T) {
+	_, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><script>var script_root = ""; var csrf_nonce = "abc"; var user_mode = "users";</script></html>`)
+	})
+
+	mux.HandleFunc("/api/v1/challenges", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "data": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]}`)
+	})
+
+	for _, id := range []int{1, 2} {
+		id := id
+		mux.HandleFunc(fmt.Sprintf("/api/v1/challenges/%d", id), func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"success": true, "data": {"id": %d, "name": "challenge"}}`, id)
+		})
+	}
+
+	var requests int
+	mux.HandleFunc("/api/v1/users/me/solves", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"success": true, "data": [{"challenge_id": 2}]}`)
+	})
+
+	if _, err := ListChallenges(); err != nil {
+		t.Fatalf("ListChallenges() returned error: %v", err)
+	}
+
+	got := make(map[int64]bool)
+	for _, id := range []int64{1, 2} {
+		challenge, err := Challenge(id)
+		if err != nil {
+			t.Fatalf("Challenge(%d) returned error: %v", id, err)
+		}
+		got[id] = challenge.SolvedByMe
+	}
+
+	if want := map[int64]bool{1: false, 2: true}; !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the solves to be requested once, got %d requests", requests)
+	}
+}
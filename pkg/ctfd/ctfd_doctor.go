@@ -0,0 +1,313 @@
+package ctfd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ritchies/ctftool/pkg/scraper"
+)
+
+// Verdict is the outcome of a diagnostic check.
+type Verdict string
+
+const (
+	Pass Verdict = "pass"
+	Warn Verdict = "warn"
+	Fail Verdict = "fail"
+)
+
+// Diagnostic is the result of a single check of Diagnose.
+type Diagnostic struct {
+	Check   string  `json:"check"`
+	Verdict Verdict `json:"verdict"`
+	Message string  `json:"message"`
+}
+
+// maxClockSkew is the clock difference with the server above which a warning is reported.
+const maxClockSkew = 30 * time.Second
+
+// doctor runs the checks of Diagnose and keeps track of the responses it observed.
+type doctor struct {
+	results     []Diagnostic
+	rateLimited int
+	retryAfter  string
+	limitHeader string
+}
+
+// Diagnose checks the instance and the configuration of the client: TLS, clock
+// skew, the CTFd version and user mode, the credentials, the visibility of the
+// challenges, whether the CTF is paused and rate limiting. Every check is run, even when an earlier one fails.
+func Diagnose() []Diagnostic {
+	d := &doctor{}
+
+	resp, body, err := d.get("")
+	if err != nil {
+		d.add("reachability", Fail, "%v", err)
+		return d.results
+	}
+	d.add("reachability", Pass, "%s answered with %s", client.BaseURL, resp.Status)
+
+	d.checkTLS(resp)
+	d.checkClock(resp)
+	d.checkVersion(body)
+	d.checkAuth()
+	d.checkChallenges()
+	d.checkPaused()
+	d.checkRateLimit()
+
+	return d.results
+}
+
+func (d *doctor) add(check string, verdict Verdict, format string, a ...interface{}) {
+	d.results = append(d.results, Diagnostic{Check: check, Verdict: verdict, Message: fmt.Sprintf(format, a...)})
+}
+
+// get requests a path of the instance and records the rate limiting it observed.
+func (d *doctor) get(path string, a ...interface{}) (*http.Response, []byte, error) {
+	resp, err := getOnce(fmt.Sprintf(path, a...))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		d.rateLimited++
+		d.retryAfter = resp.Header.Get("Retry-After")
+	}
+	for _, header := range []string{"X-RateLimit-Limit", "RateLimit-Limit", "RateLimit-Policy"} {
+		if value := resp.Header.Get(header); value != "" {
+			d.limitHeader = fmt.Sprintf("%s: %s", header, value)
+		}
+	}
+
+	return resp, body, nil
+}
+
+func (d *doctor) checkTLS(resp *http.Response) {
+	if resp.TLS == nil {
+		if resp.Request.URL.Scheme == "http" {
+			d.add("tls", Warn, "plain HTTP, credentials are sent in clear text")
+		} else {
+			d.add("tls", Warn, "no TLS details, the response was replayed or cached")
+		}
+		return
+	}
+
+	state := resp.TLS
+	message := fmt.Sprintf("%s, %s", tlsVersionName(state.Version), tls.CipherSuiteName(state.CipherSuite))
+	verdict := Pass
+
+	if state.Version < tls.VersionTLS12 {
+		verdict = Warn
+		message += ", outdated protocol version"
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		message += fmt.Sprintf(", certificate for %q issued by %q expires %s", cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter.Format("2006-01-02"))
+
+		if until := time.Until(cert.NotAfter); until < 14*24*time.Hour {
+			verdict = Warn
+			message += fmt.Sprintf(" (in %d days)", int(until.Hours()/24))
+		}
+	}
+
+	d.add("tls", verdict, "%s", message)
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return fmt.Sprintf("TLS 0x%04x", version)
+}
+
+func (d *doctor) checkClock(resp *http.Response) {
+	// cached responses carry the date they were stored
+	if resp.Header.Get("X-Ctftool-Cache") != "" {
+		d.add("clock", Warn, "response served from the cache, clock skew unknown")
+		return
+	}
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		d.add("clock", Warn, "server did not send a valid Date header")
+		return
+	}
+
+	skew := time.Since(date).Round(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew > maxClockSkew {
+		d.add("clock", Warn, "local clock is %s off from the server, start and end times will be shifted", skew)
+		return
+	}
+
+	d.add("clock", Pass, "local clock is within %s of the server", maxClockSkew)
+}
+
+func (d *doctor) checkVersion(body []byte) {
+	version, err := fingerprintHTML(body)
+	if err != nil || version.Major == 0 {
+		d.add("version", Warn, "could not fingerprint the CTFd version, this may not be a CTFd instance")
+		return
+	}
+
+	message := fmt.Sprintf("CTFd %s", version.Version)
+	if version.Theme != "" {
+		message += fmt.Sprintf(", theme %q", version.Theme)
+	}
+	d.add("version", Pass, "%s", message)
+
+	switch version.UserMode {
+	case "":
+		d.add("mode", Warn, "could not detect the user mode")
+	case "teams":
+		d.add("mode", Pass, "team mode, a team has to be joined to play")
+	default:
+		d.add("mode", Pass, "%s mode", version.UserMode)
+	}
+}
+
+func (d *doctor) checkAuth() {
+	creds := client.Creds
+	if creds == nil {
+		creds = &scraper.Credentials{}
+	}
+
+	method := ""
+	switch {
+	case creds.Token != "":
+		method = "token"
+	case creds.Username != "" && creds.Password != "":
+		method = "password"
+		if err := Authenticate(); err != nil {
+			d.add("auth", Fail, "%s login failed: %v", method, err)
+			return
+		}
+	}
+
+	resp, body, err := d.get("api/v1/users/me")
+	if err != nil {
+		d.add("auth", Fail, "%v", err)
+		return
+	}
+
+	var me struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(body, &me) != nil || !me.Success {
+		if method == "" {
+			d.add("auth", Warn, "no credentials configured, only public data is visible")
+			return
+		}
+		d.add("auth", Fail, "%s is not valid: /api/v1/users/me answered with %s", method, resp.Status)
+		return
+	}
+
+	if method == "" {
+		method = "session cookie"
+	}
+	d.add("auth", Pass, "%s is valid, logged in as %q", method, me.Data.Name)
+}
+
+func (d *doctor) checkChallenges() {
+	resp, body, err := d.get("api/v1/challenges")
+	if err != nil {
+		d.add("challenges", Fail, "%v", err)
+		return
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Message string            `json:"message"`
+		Data    []json.RawMessage `json:"data"`
+	}
+	_ = json.Unmarshal(body, &response)
+
+	message := strings.ToLower(response.Message)
+	switch {
+	case resp.StatusCode == http.StatusOK && response.Success:
+		if len(response.Data) == 0 {
+			d.add("challenges", Warn, "no challenges are visible yet")
+			return
+		}
+		d.add("challenges", Pass, "%d challenges are visible", len(response.Data))
+	case strings.Contains(message, "not started"):
+		d.add("challenges", Warn, "the CTF has not started yet")
+	case strings.Contains(message, "ended"):
+		d.add("challenges", Warn, "the CTF has ended")
+	case strings.Contains(message, "paused"):
+		d.add("challenges", Warn, "the CTF is paused")
+	case strings.HasSuffix(resp.Request.URL.Path, "/login"), resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusUnauthorized:
+		d.add("challenges", Fail, "challenges are not visible, authentication or a team is required")
+	default:
+		d.add("challenges", Fail, "/api/v1/challenges answered with %s", resp.Status)
+	}
+}
+
+// checkPaused looks for the notice of a paused CTF on the challenges page. A
+// paused CTF still lists its challenges, CTFd shows "<ctf name> is paused" in
+// the infos of the page and rejects the submissions.
+func (d *doctor) checkPaused() {
+	resp, body, err := d.get("challenges")
+	if err != nil || resp.StatusCode != http.StatusOK || strings.HasSuffix(resp.Request.URL.Path, "/login") {
+		// the challenges check already reports why the page isn't visible
+		return
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	paused := false
+	doc.Find(".alert").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		paused = strings.Contains(strings.ToLower(s.Text()), "is paused")
+		return !paused
+	})
+
+	if paused {
+		d.add("paused", Warn, "the CTF is paused, submissions are rejected")
+		return
+	}
+	d.add("paused", Pass, "the CTF is not paused")
+}
+
+func (d *doctor) checkRateLimit() {
+	switch {
+	case d.rateLimited > 0 && d.retryAfter != "":
+		d.add("rate-limit", Warn, "rate limited %d times, the server asked to retry after %ss", d.rateLimited, d.retryAfter)
+	case d.rateLimited > 0:
+		d.add("rate-limit", Warn, "rate limited %d times, lower --rate-limit", d.rateLimited)
+	case d.limitHeader != "":
+		d.add("rate-limit", Pass, "no rate limiting observed, the server announces %s", d.limitHeader)
+	default:
+		d.add("rate-limit", Pass, "no rate limiting observed")
+	}
+}
@@ -0,0 +1,110 @@
+package ctfd
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ritchies/ctftool/pkg/scraper"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		description string
+		token       string
+		date        time.Time
+		paused      bool
+		challenges  func(w http.ResponseWriter)
+		want        map[string]Verdict
+	}{
+		{
+			"healthy instance",
+			"valid",
+			time.Now(),
+			false,
+			func(w http.ResponseWriter) {
+				fmt.Fprint(w, `{"success": true, "data": [{"id": 1}, {"id": 2}]}`)
+			},
+			map[string]Verdict{"reachability": Pass, "tls": Warn, "clock": Pass, "version": Pass, "mode": Pass, "auth": Pass, "challenges": Pass, "paused": Pass, "rate-limit": Pass},
+		},
+		{
+			"paused",
+			"valid",
+			time.Now(),
+			true,
+			func(w http.ResponseWriter) {
+				fmt.Fprint(w, `{"success": true, "data": [{"id": 1}]}`)
+			},
+			map[string]Verdict{"challenges": Pass, "paused": Warn},
+		},
+		{
+			"not started, skewed clock and invalid token",
+			"invalid",
+			time.Now().Add(-10 * time.Minute),
+			false,
+			func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "Demo has not started yet"}`)
+			},
+			map[string]Verdict{"clock": Warn, "auth": Fail, "challenges": Warn},
+		},
+		{
+			"rate limited",
+			"",
+			time.Now(),
+			false,
+			func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			map[string]Verdict{"auth": Warn, "challenges": Fail, "rate-limit": Warn},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			client, mux, cleanup := setup()
+			defer cleanup()
+
+			client.Creds = &scraper.Credentials{Token: test.token}
+			defer func() { client.Creds = &scraper.Credentials{} }()
+
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Date", test.date.UTC().Format(http.TimeFormat))
+				fmt.Fprint(w, `<html><script>window.init = {'csrfNonce': "abc", 'userMode': "teams"}</script></html>`)
+			})
+
+			mux.HandleFunc("/challenges", func(w http.ResponseWriter, r *http.Request) {
+				if test.paused {
+					fmt.Fprint(w, `<html><body><div class="container"><div class="alert alert-info text-center" role="alert">Demo is paused</div></div></body></html>`)
+					return
+				}
+				fmt.Fprint(w, `<html><body><div class="container"><div id="challenges-board"></div></div></body></html>`)
+			})
+
+			mux.HandleFunc("/api/v1/users/me", func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Token valid" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprint(w, `{"success": true, "data": {"name": "alice"}}`)
+			})
+
+			mux.HandleFunc("/api/v1/challenges", func(w http.ResponseWriter, r *http.Request) {
+				test.challenges(w)
+			})
+
+			got := make(map[string]Verdict)
+			for _, diagnostic := range Diagnose() {
+				got[diagnostic.Check] = diagnostic.Verdict
+			}
+
+			for check, verdict := range test.want {
+				if got[check] != verdict {
+					t.Errorf("check %q: got %q, want %q", check, got[check], verdict)
+				}
+			}
+		})
+	}
+}
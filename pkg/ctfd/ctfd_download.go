@@ -0,0 +1,84 @@
+package ctfd
+
+import "sync"
+
+// DefaultDownloadConcurrency is the number of files downloaded at the same time
+// when SetDownloadConcurrency was not called.
+const DefaultDownloadConcurrency = 4
+
+// Progress receives the progress of file downloads. Its methods are called
+// from several goroutines at once.
+type Progress interface {
+	// Start is called once the size of the file is known, -1 when it isn't.
+	Start(file string, size int64)
+	// Add is called for every chunk written to disk.
+	Add(file string, n int64)
+	// Done is called when the file is written, or with the error that stopped it.
+	Done(file string, err error)
+}
+
+var (
+	downloadsMu sync.Mutex
+	// downloadSlots bounds the downloads of every challenge together, CTFd
+	// throttles clients with too many connections
+	downloadSlots = make(chan struct{}, DefaultDownloadConcurrency)
+	progress      Progress
+)
+
+// SetDownloadConcurrency sets how many files are downloaded at the same time,
+// across all challenges. Values below 1 are treated as 1.
+//
+//	ctfd.SetDownloadConcurrency(8)
+func SetDownloadConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	downloadsMu.Lock()
+	downloadSlots = make(chan struct{}, n)
+	downloadsMu.Unlock()
+}
+
+// SetProgress sets where the progress of file downloads is reported, nil disables it.
+func SetProgress(p Progress) {
+	downloadsMu.Lock()
+	progress = p
+	downloadsMu.Unlock()
+}
+
+// acquireDownloadSlot waits for a free download slot and returns the function releasing it.
+func acquireDownloadSlot() func() {
+	downloadsMu.Lock()
+	slots := downloadSlots
+	downloadsMu.Unlock()
+
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+func downloadProgress() Progress {
+	downloadsMu.Lock()
+	defer downloadsMu.Unlock()
+
+	if progress == nil {
+		return noProgress{}
+	}
+	return progress
+}
+
+type noProgress struct{}
+
+func (noProgress) Start(string, int64) {}
+func (noProgress) Add(string, int64)   {}
+func (noProgress) Done(string, error)  {}
+
+// progressWriter reports the bytes written through it.
+type progressWriter struct {
+	progress Progress
+	name     string
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.progress.Add(w.name, int64(len(p)))
+	return len(p), nil
+}
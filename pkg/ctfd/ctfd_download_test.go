@@ -0,0 +1,102 @@
+package ctfd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedProgress struct {
+	mu      sync.Mutex
+	started map[string]int64
+	written map[string]int64
+	done    map[string]error
+}
+
+func (p *recordedProgress) Start(file string, size int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.started[file] = size
+}
+
+func (p *recordedProgress) Add(file string, n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.written[file] += n
+}
+
+func (p *recordedProgress) Done(file string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done[file] = err
+}
+
+func TestDownloadFiles(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.MaxFileSize = 1
+
+	var mu sync.Mutex
+	var active, maxActive int
+
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, "content of "+path.Base(r.URL.Path))
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+	})
+
+	recorded := &recordedProgress{started: map[string]int64{}, written: map[string]int64{}, done: map[string]error{}}
+	SetDownloadConcurrency(2)
+	SetProgress(recorded)
+	defer SetDownloadConcurrency(DefaultDownloadConcurrency)
+	defer SetProgress(nil)
+
+	dir := path.Join(t.TempDir(), "challenge")
+	if err := os.Mkdir(dir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	for i := 0; i < 6; i++ {
+		files = append(files, fmt.Sprintf("files/file%d.txt?token=abc", i))
+	}
+
+	if err := DownloadFiles(files, dir); err != nil {
+		t.Fatalf("DownloadFiles() returned error: %v", err)
+	}
+
+	if maxActive > 2 {
+		t.Errorf("expected at most 2 concurrent downloads, got %d", maxActive)
+	}
+
+	for i := 0; i < 6; i++ {
+		name := fmt.Sprintf("file%d.txt", i)
+		content, err := os.ReadFile(path.Join(dir, name))
+		if err != nil {
+			t.Errorf("expected %s to be downloaded: %v", name, err)
+			continue
+		}
+
+		key := "challenge/" + name
+		if recorded.written[key] != int64(len(content)) || recorded.started[key] != int64(len(content)) {
+			t.Errorf("got progress %d/%d for %s, want %d", recorded.written[key], recorded.started[key], key, len(content))
+		}
+		if err, ok := recorded.done[key]; !ok || err != nil {
+			t.Errorf("expected %s to be done without error, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ctfd
+
+import "math"
+
+// DynamicValue is how the value of a dynamic challenge decays with its
+// solves. The function is logarithmic, the default of CTFd, or linear.
+type DynamicValue struct {
+	Initial  float64 `json:"initial,omitempty"`
+	Minimum  float64 `json:"minimum,omitempty"`
+	Decay    float64 `json:"decay,omitempty"`
+	Function string  `json:"function,omitempty"`
+}
+
+// Known reports whether the decay of the value is exposed.
+func (d DynamicValue) Known() bool {
+	return d.Initial > 0 && d.Decay > 0
+}
+
+// ValueAt returns the value of the challenge once it has that many solves,
+// computed like CTFd does. The first solve doesn't decay the value and the
+// value never goes below the minimum.
+func (d DynamicValue) ValueAt(solves int64) int64 {
+	n := float64(solves)
+	if n > 0 {
+		n--
+	}
+
+	var value float64
+	switch d.Function {
+	case "linear":
+		value = d.Initial - d.Decay*n
+	default:
+		value = (d.Minimum-d.Initial)/(d.Decay*d.Decay)*(n*n) + d.Initial
+	}
+
+	value = math.Ceil(value)
+	if value < d.Minimum {
+		value = d.Minimum
+	}
+
+	return int64(value)
+}
+
+// Projection is the value of an unsolved dynamic challenge if we solve it
+// now, and the points lost by letting another account solve it first.
+type Projection struct {
+	Expected int64 `json:"expected"`
+	Loss     int64 `json:"loss"`
+}
+
+// Project returns the projection of the challenge at its current solves.
+func (d DynamicValue) Project(solves int64) Projection {
+	expected := d.ValueAt(solves + 1)
+	return Projection{Expected: expected, Loss: expected - d.ValueAt(solves+2)}
+}
+
+// Scoring returns the decay of the value of the challenge, from its fields or
+// from its type data for the plugins that expose it there. ok is false when
+// the value is static or its decay is hidden.
+func (c *ChallengeData) Scoring() (scoring DynamicValue, ok bool) {
+	if c.DynamicValue.Known() {
+		return c.DynamicValue, true
+	}
+
+	if c.TypeData.DynamicValue.Known() {
+		return c.TypeData.DynamicValue, true
+	}
+
+	return DynamicValue{}, false
+}
@@ -0,0 +1,78 @@
+package ctfd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDynamicValue_ValueAt(t *testing.T) {
+	logarithmic := DynamicValue{Initial: 500, Minimum: 100, Decay: 15}
+	linear := DynamicValue{Initial: 500, Minimum: 100, Decay: 30, Function: "linear"}
+
+	tests := []struct {
+		description string
+		scoring     DynamicValue
+		solves      int64
+		want        int64
+	}{
+		{description: "no solves", scoring: logarithmic, solves: 0, want: 500},
+		{description: "the first solve doesn't decay", scoring: logarithmic, solves: 1, want: 500},
+		{description: "logarithmic rounds up", scoring: logarithmic, solves: 5, want: 472},
+		{description: "logarithmic reaches the minimum at decay solves", scoring: logarithmic, solves: 16, want: 100},
+		{description: "logarithmic never goes below the minimum", scoring: logarithmic, solves: 40, want: 100},
+		{description: "linear", scoring: linear, solves: 5, want: 380},
+		{description: "linear never goes below the minimum", scoring: linear, solves: 40, want: 100},
+	}
+
+	for _, test := range tests {
+		if got := test.scoring.ValueAt(test.solves); got != test.want {
+			t.Errorf("%s: got %d, want %d", test.description, got, test.want)
+		}
+	}
+}
+
+func TestDynamicValue_Project(t *testing.T) {
+	scoring := DynamicValue{Initial: 500, Minimum: 100, Decay: 30, Function: "linear"}
+
+	if got, want := scoring.Project(4), (Projection{Expected: 380, Loss: 30}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestChallengeData_Scoring(t *testing.T) {
+	tests := []struct {
+		description string
+		data        string
+		want        DynamicValue
+		wantOK      bool
+	}{
+		{
+			description: "challenge fields",
+			data:        `{"type": "dynamic", "value": 480, "initial": 500, "minimum": 100, "decay": 15, "function": "logarithmic"}`,
+			want:        DynamicValue{Initial: 500, Minimum: 100, Decay: 15, Function: "logarithmic"},
+			wantOK:      true,
+		},
+		{
+			description: "type data",
+			data:        `{"type": "dynamic", "type_data": {"id": "dynamic", "initial": 300, "minimum": 50, "decay": 10}}`,
+			want:        DynamicValue{Initial: 300, Minimum: 50, Decay: 10},
+			wantOK:      true,
+		},
+		{
+			description: "static value",
+			data:        `{"type": "standard", "value": 100}`,
+		},
+	}
+
+	for _, test := range tests {
+		var challenge ChallengeData
+		if err := json.Unmarshal([]byte(test.data), &challenge); err != nil {
+			t.Fatalf("%s: failed to decode challenge: %v", test.description, err)
+		}
+
+		got, ok := challenge.Scoring()
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("%s: got %+v, %v, want %+v, %v", test.description, got, ok, test.want, test.wantOK)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package ctfd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ritchies/ctftool/internal/lib"
+)
+
+// Range is an inclusive range of integers, either bound can be left open.
+type Range struct {
+	Min    int64
+	Max    int64
+	HasMin bool
+	HasMax bool
+}
+
+// ParseRange parses ranges like "300", "300-", "-500" and "100-500".
+func ParseRange(s string) (Range, error) {
+	var r Range
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return r, nil
+	}
+
+	min, max, isRange := strings.Cut(s, "-")
+	if !isRange {
+		max = min
+	}
+
+	if min = strings.TrimSpace(min); min != "" {
+		value, err := strconv.ParseInt(min, 10, 64)
+		if err != nil {
+			return r, fmt.Errorf("invalid range %q", s)
+		}
+		r.Min, r.HasMin = value, true
+	}
+
+	if max = strings.TrimSpace(max); max != "" {
+		value, err := strconv.ParseInt(max, 10, 64)
+		if err != nil {
+			return r, fmt.Errorf("invalid range %q", s)
+		}
+		r.Max, r.HasMax = value, true
+	}
+
+	if r.HasMin && r.HasMax && r.Min > r.Max {
+		return r, fmt.Errorf("invalid range %q: %d is larger than %d", s, r.Min, r.Max)
+	}
+
+	return r, nil
+}
+
+// Contains reports whether the value is within the range.
+func (r Range) Contains(value int64) bool {
+	return (!r.HasMin || value >= r.Min) && (!r.HasMax || value <= r.Max)
+}
+
+func (r Range) String() string {
+	switch {
+	case r.HasMin && r.HasMax && r.Min == r.Max:
+		return strconv.FormatInt(r.Min, 10)
+	case r.HasMin && r.HasMax:
+		return fmt.Sprintf("%d-%d", r.Min, r.Max)
+	case r.HasMin:
+		return fmt.Sprintf("%d-", r.Min)
+	case r.HasMax:
+		return fmt.Sprintf("-%d", r.Max)
+	}
+	return ""
+}
+
+// Filter selects challenges. Every set criterion has to match, a criterion
+// with several values matches when any of its values does.
+type Filter struct {
+	Categories   []string       // raw or slugged category names
+	IDs          []int64        // challenge IDs
+	Name         *regexp.Regexp // pattern matched against the challenge name
+	Value        Range          // points
+	Solves       Range          // solve count
+	Tags         []string       // tag values
+	UnsolvedOnly bool           // skip challenges solved by the user or team
+	Layout       *Layout        // categories also match the aliases of the layout
+}
+
+// Match reports whether the challenge matches the filter, along with the
+// reason it does not.
+func (f *Filter) Match(challenge ChallengesData) (bool, string) {
+	if f == nil {
+		return true, ""
+	}
+
+	if f.UnsolvedOnly && challenge.SolvedByMe {
+		return false, "already solved"
+	}
+
+	if len(f.IDs) > 0 && !containsID(f.IDs, challenge.ID) {
+		return false, "ID not selected"
+	}
+
+	if len(f.Categories) > 0 && !f.matchCategory(challenge) {
+		return false, fmt.Sprintf("category %q not selected", challenge.Category)
+	}
+
+	if f.Name != nil && !f.Name.MatchString(challenge.Name) {
+		return false, fmt.Sprintf("name does not match %q", f.Name)
+	}
+
+	if !f.Value.Contains(challenge.Value) {
+		return false, fmt.Sprintf("value %d not in %s", challenge.Value, f.Value)
+	}
+
+	if !f.Solves.Contains(challenge.Solves) {
+		return false, fmt.Sprintf("%d solves not in %s", challenge.Solves, f.Solves)
+	}
+
+	if len(f.Tags) > 0 && !matchTags(f.Tags, challenge.Tags) {
+		return false, "no selected tag"
+	}
+
+	return true, ""
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCategory matches the raw category, its slug, the slug of its first
+// word and its alias in the layout.
+func (f *Filter) matchCategory(challenge ChallengesData) bool {
+	category := challenge.Category
+	candidates := []string{
+		strings.ToLower(strings.TrimSpace(category)),
+		lib.CleanSlug(category, true),
+		lib.CleanSlug(strings.Split(category, " ")[0], true),
+	}
+
+	if f.Layout != nil {
+		candidates = append(candidates, f.Layout.Category(challenge))
+	}
+
+	for _, c := range f.Categories {
+		want := strings.ToLower(strings.TrimSpace(c))
+		slug := lib.CleanSlug(c, true)
+
+		for _, candidate := range candidates {
+			if candidate != "" && (candidate == want || candidate == slug) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchTags(want []string, tags []Tag) bool {
+	for _, w := range want {
+		for _, tag := range tags {
+			if strings.EqualFold(strings.TrimSpace(w), strings.TrimSpace(tag.Value)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FindChallenge returns the challenge whose name best matches the query. An
+// exact name wins over a prefix, a prefix over a substring and a substring over
+// the letters of the query appearing in order, case and punctuation are ignored.
+// The query is ambiguous when several challenges match equally well.
+func FindChallenge(challenges []ChallengesData, query string) (*ChallengesData, error) {
+	want := fuzzyKey(query)
+	if want == "" {
+		return nil, fmt.Errorf("empty challenge name")
+	}
+
+	var best []ChallengesData
+	bestScore := 0
+
+	for _, challenge := range challenges {
+		score := fuzzyScore(fuzzyKey(challenge.Name), want)
+		switch {
+		case score == 0 || score < bestScore:
+			continue
+		case score > bestScore:
+			best, bestScore = nil, score
+		}
+		best = append(best, challenge)
+	}
+
+	switch len(best) {
+	case 0:
+		return nil, fmt.Errorf("no challenge matches %q", query)
+	case 1:
+		return &best[0], nil
+	}
+
+	var names []string
+	for _, challenge := range best {
+		names = append(names, fmt.Sprintf("%q (%d)", challenge.Name, challenge.ID))
+	}
+
+	return nil, fmt.Errorf("%q matches %d challenges: %s", query, len(best), strings.Join(names, ", "))
+}
+
+// fuzzyKey lowercases a name and drops everything but letters and digits.
+func fuzzyKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fuzzyScore rates how well the name matches the query, 0 is no match.
+func fuzzyScore(name string, query string) int {
+	switch {
+	case name == query:
+		return 4
+	case strings.HasPrefix(name, query):
+		return 3
+	case strings.Contains(name, query):
+		return 2
+	}
+
+	// the letters of the query in order, e.g. "brp" matches "baby rop"
+	rest := name
+	for _, r := range query {
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return 0
+		}
+		rest = rest[i+len(string(r)):]
+	}
+
+	return 1
+}
@@ -0,0 +1,135 @@
+package ctfd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Range
+		wantErr bool
+	}{
+		{"", Range{}, false},
+		{"300", Range{Min: 300, Max: 300, HasMin: true, HasMax: true}, false},
+		{"300-", Range{Min: 300, HasMin: true}, false},
+		{"-500", Range{Max: 500, HasMax: true}, false},
+		{"100-500", Range{Min: 100, Max: 500, HasMin: true, HasMax: true}, false},
+		{"500-100", Range{}, true},
+		{"abc", Range{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseRange(test.input)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseRange() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			if !test.wantErr && !cmp.Equal(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+
+			if !test.wantErr && got.String() != test.input {
+				t.Errorf("String() = %q, want %q", got.String(), test.input)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	challenge := ChallengesData{
+		ID:       7,
+		Name:     "Baby ROP",
+		Category: "Binary Exploitation",
+		Value:    300,
+		Solves:   12,
+		Tags:     []Tag{{Value: "easy"}},
+	}
+
+	tests := []struct {
+		description string
+		filter      *Filter
+		want        bool
+	}{
+		{"no filter", nil, true},
+		{"empty filter", &Filter{}, true},
+		{"raw category", &Filter{Categories: []string{"binary exploitation"}}, true},
+		{"slugged category", &Filter{Categories: []string{"binary-exploitation"}}, true},
+		{"category folder", &Filter{Categories: []string{"binary"}}, true},
+		{"other category", &Filter{Categories: []string{"web", "crypto"}}, false},
+		{"id", &Filter{IDs: []int64{3, 7}}, true},
+		{"other id", &Filter{IDs: []int64{3}}, false},
+		{"name regex", &Filter{Name: regexp.MustCompile(`(?i)rop`)}, true},
+		{"value range", &Filter{Value: Range{Min: 300, HasMin: true}}, true},
+		{"value too low", &Filter{Value: Range{Min: 400, HasMin: true}}, false},
+		{"solves range", &Filter{Solves: Range{Max: 10, HasMax: true}}, false},
+		{"tag", &Filter{Tags: []string{"EASY"}}, true},
+		{"other tag", &Filter{Tags: []string{"hard"}}, false},
+		{"combined", &Filter{Categories: []string{"binary"}, Value: Range{Min: 100, Max: 500, HasMin: true, HasMax: true}, Tags: []string{"easy"}}, true},
+		{"combined with one mismatch", &Filter{Categories: []string{"binary"}, IDs: []int64{1}}, false},
+		{"unsolved only", &Filter{UnsolvedOnly: true}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, reason := test.filter.Match(challenge)
+			if got != test.want {
+				t.Errorf("Match() = %v (%s), want %v", got, reason, test.want)
+			}
+
+			if !got && reason == "" {
+				t.Error("expected a reason for skipping the challenge")
+			}
+		})
+	}
+}
+
+func TestFindChallenge(t *testing.T) {
+	challenges := []ChallengesData{
+		{ID: 1, Name: "Baby ROP"},
+		{ID: 2, Name: "Baby ROP 2"},
+		{ID: 3, Name: "XSS me"},
+		{ID: 4, Name: "Cookie Monster"},
+		{ID: 5, Name: "Cookies"},
+	}
+
+	tests := []struct {
+		description string
+		query       string
+		want        int64
+		wantErr     bool
+	}{
+		{"exact name", "baby rop", 1, false},
+		{"exact name ignores punctuation", "baby-rop-2", 2, false},
+		{"prefix", "xss", 3, false},
+		{"substring", "monster", 4, false},
+		{"letters in order", "cmnstr", 4, false},
+		{"ambiguous prefix", "cookie", 0, true},
+		{"no match", "heap", 0, true},
+		{"empty", " ", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := FindChallenge(challenges, test.query)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got challenge %d", got.ID)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("FindChallenge() returned error: %v", err)
+			}
+
+			if got.ID != test.want {
+				t.Errorf("got challenge %d, want %d", got.ID, test.want)
+			}
+		})
+	}
+}
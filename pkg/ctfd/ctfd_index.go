@@ -0,0 +1,306 @@
+package ctfd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IndexFormats are the formats GenerateIndex can write, "md" is the default.
+var IndexFormats = []string{"md", "json", "csv", "html"}
+
+// Index is the data model shared by every index format.
+type Index struct {
+	Generated  time.Time       `json:"generated"`
+	Points     int64           `json:"points"`
+	Solved     int             `json:"solved"`
+	Total      int             `json:"total"`
+	Categories []IndexCategory `json:"categories"`
+}
+
+// IndexCategory holds the challenges and totals of a category.
+type IndexCategory struct {
+	Name       string       `json:"name"`
+	Points     int64        `json:"points"`
+	MaxPoints  int64        `json:"max_points"`
+	Solved     int          `json:"solved"`
+	Challenges []IndexEntry `json:"challenges"`
+}
+
+// IndexEntry is a challenge of the index.
+type IndexEntry struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Value    int64    `json:"value"`
+	Solves   int64    `json:"solves"`
+	Solved   bool     `json:"solved"`
+	Tags     []string `json:"tags"`
+	Path     string   `json:"path"` // writeup of the challenge, relative to the index
+}
+
+// NewIndex groups the challenges by category, following the folders of the layout.
+// Categories are sorted alphabetically, challenges by name.
+func NewIndex(challenges []ChallengesData, layout *Layout) *Index {
+	paths := layout.Paths(challenges)
+
+	index := &Index{Generated: time.Now()}
+	categories := make(map[string]*IndexCategory)
+
+	for _, challenge := range challenges {
+		challengePath, ok := paths[challenge.ID]
+		if !ok {
+			continue
+		}
+
+		name := layout.Category(challenge)
+		category, ok := categories[name]
+		if !ok {
+			category = &IndexCategory{Name: name}
+			categories[name] = category
+		}
+
+		entry := IndexEntry{
+			ID:       challenge.ID,
+			Name:     challenge.Name,
+			Category: name,
+			Value:    challenge.Value,
+			Solves:   challenge.Solves,
+			Solved:   challenge.SolvedByMe,
+			Tags:     []string{},
+			Path:     challengePath + "/README.md",
+		}
+		for _, tag := range challenge.Tags {
+			entry.Tags = append(entry.Tags, tag.Value)
+		}
+
+		category.Challenges = append(category.Challenges, entry)
+		category.MaxPoints += challenge.Value
+		index.Total++
+
+		if challenge.SolvedByMe {
+			category.Points += challenge.Value
+			category.Solved++
+			index.Points += challenge.Value
+			index.Solved++
+		}
+	}
+
+	for _, category := range categories {
+		sort.Slice(category.Challenges, func(i, j int) bool {
+			return category.Challenges[i].Name < category.Challenges[j].Name
+		})
+		index.Categories = append(index.Categories, *category)
+	}
+
+	sort.Slice(index.Categories, func(i, j int) bool {
+		return index.Categories[i].Name < index.Categories[j].Name
+	})
+
+	return index
+}
+
+// Entries returns the challenges of every category.
+func (index *Index) Entries() []IndexEntry {
+	var entries []IndexEntry
+	for _, category := range index.Categories {
+		entries = append(entries, category.Challenges...)
+	}
+	return entries
+}
+
+// GenerateIndex writes an index of all challenges in their respective categories for every format,
+// as index.md, index.json, index.csv and index.html. Without formats, only index.md is written.
+// The links follow the folders of the layout, a nil layout uses the default one.
+func GenerateIndex(challenges []ChallengesData, layout *Layout, outputPath string, formats ...string) error {
+	if layout == nil {
+		var err error
+		if layout, err = NewLayout("", nil); err != nil {
+			return err
+		}
+	}
+
+	if len(formats) == 0 {
+		formats = []string{"md"}
+	}
+
+	index := NewIndex(challenges, layout)
+
+	for _, format := range formats {
+		if err := writeIndexFile(index, format, path.Join(outputPath, "index."+strings.ToLower(format))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeIndexFile(index *Index, format string, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+
+	if err := WriteIndex(index, format, file); err != nil {
+		return fmt.Errorf("error writing to file: %v", err)
+	}
+
+	return nil
+}
+
+// WriteIndex renders the index in the given format.
+func WriteIndex(index *Index, format string, w io.Writer) error {
+	switch strings.ToLower(format) {
+	case "md", "markdown":
+		return writeMarkdownIndex(index, w)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(index)
+	case "csv":
+		return writeCSVIndex(index, w)
+	case "html":
+		return htmlIndexTemplate.Execute(w, index)
+	}
+
+	return fmt.Errorf("unknown index format %q, expected one of %s", format, strings.Join(IndexFormats, ", "))
+}
+
+func writeMarkdownIndex(index *Index, w io.Writer) error {
+	if _, err := io.WriteString(w, "# Index\n\n"); err != nil {
+		return err
+	}
+
+	for _, category := range index.Categories {
+		if _, err := fmt.Fprintf(w, "## %s\n\n", strings.ToUpper(category.Name)); err != nil {
+			return err
+		}
+
+		for _, challenge := range category.Challenges {
+			var solved string
+			// Check if solved by me
+			if challenge.Solved {
+				solved = "✅"
+			} else {
+				solved = "❌"
+			}
+
+			if _, err := fmt.Fprintf(w, "- %s [%s](%s)\n", solved, challenge.Name, challenge.Path); err != nil {
+				return err
+			}
+		}
+
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeCSVIndex(index *Index, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"id", "name", "category", "value", "solves", "solved", "tags", "path"}); err != nil {
+		return err
+	}
+
+	for _, challenge := range index.Entries() {
+		record := []string{
+			strconv.FormatInt(challenge.ID, 10),
+			CSVField(challenge.Name),
+			CSVField(challenge.Category),
+			strconv.FormatInt(challenge.Value, 10),
+			strconv.FormatInt(challenge.Solves, 10),
+			strconv.FormatBool(challenge.Solved),
+			CSVField(strings.Join(challenge.Tags, ";")),
+			CSVField(challenge.Path),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// CSVField escapes a field a spreadsheet would evaluate as a formula, challenge
+// names and tags come from the CTF organizers.
+func CSVField(field string) string {
+	if field != "" && strings.ContainsAny(field[:1], "=+-@\t\r") {
+		return "'" + field
+	}
+	return field
+}
+
+// htmlIndexTemplate is a static dashboard without external assets, so it can
+// be opened from a shared drive. Clicking a column header sorts the table.
+var htmlIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Index</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { padding: .3em .8em; border-bottom: 1px solid #ddd; text-align: left; }
+th { cursor: pointer; user-select: none; background: #f4f4f4; }
+td.num, th.num { text-align: right; }
+tr.solved td { color: #2a7a2a; }
+</style>
+</head>
+<body>
+<h1>Index</h1>
+<p>{{.Solved}}/{{.Total}} challenges solved, {{.Points}} points. Generated {{.Generated.Format "2006-01-02 15:04:05"}}.</p>
+
+<h2>Categories</h2>
+<table class="sortable">
+<thead><tr><th>Category</th><th class="num">Solved</th><th class="num">Challenges</th><th class="num">Points</th><th class="num">Max points</th></tr></thead>
+<tbody>
+{{- range .Categories}}
+<tr><td>{{.Name}}</td><td class="num">{{.Solved}}</td><td class="num">{{len .Challenges}}</td><td class="num">{{.Points}}</td><td class="num">{{.MaxPoints}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+
+<h2>Challenges</h2>
+<table class="sortable">
+<thead><tr><th class="num">ID</th><th>Name</th><th>Category</th><th class="num">Value</th><th class="num">Solves</th><th>Solved</th><th>Tags</th></tr></thead>
+<tbody>
+{{- range .Entries}}
+<tr{{if .Solved}} class="solved"{{end}}><td class="num">{{.ID}}</td><td><a href="{{.Path}}">{{.Name}}</a></td><td>{{.Category}}</td><td class="num">{{.Value}}</td><td class="num">{{.Solves}}</td><td>{{if .Solved}}✅{{else}}❌{{end}}</td><td>{{range $i, $tag := .Tags}}{{if $i}}, {{end}}{{$tag}}{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("th").forEach(function (th, column) {
+    th.addEventListener("click", function () {
+      var body = table.tBodies[0];
+      var numeric = th.classList.contains("num");
+      var ascending = th.dataset.order !== "asc";
+      th.dataset.order = ascending ? "asc" : "desc";
+      Array.from(body.rows).sort(function (a, b) {
+        var x = a.cells[column].textContent, y = b.cells[column].textContent;
+        var order = numeric ? x - y : x.localeCompare(y);
+        return ascending ? order : -order;
+      }).forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,172 @@
+package ctfd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var indexChallenges = []ChallengesData{
+	{ID: 1, Name: "Baby ROP", Category: "Binary Exploitation", Value: 300, Solves: 4, SolvedByMe: true, Tags: []Tag{{Value: "easy"}}},
+	{ID: 2, Name: "XSS me", Category: "Web", Value: 100, Solves: 40},
+	{ID: 3, Name: "Cookies", Category: "Web", Value: 200, Solves: 10, SolvedByMe: true},
+}
+
+func newTestIndex(t *testing.T) *Index {
+	layout, err := NewLayout("{{.Category}}/{{.Value}}-{{.Name}}", map[string]string{"Binary Exploitation": "pwn"})
+	if err != nil {
+		t.Fatalf("NewLayout() returned error: %v", err)
+	}
+
+	return NewIndex(indexChallenges, layout)
+}
+
+func TestNewIndex(t *testing.T) {
+	index := newTestIndex(t)
+
+	if index.Total != 3 || index.Solved != 2 || index.Points != 500 {
+		t.Errorf("got totals %d/%d with %d points", index.Solved, index.Total, index.Points)
+	}
+
+	var got []string
+	for _, category := range index.Categories {
+		got = append(got, category.Name)
+	}
+	if want := []string{"pwn", "web"}; !cmp.Equal(got, want) {
+		t.Errorf("got categories %v, want %v", got, want)
+	}
+
+	web := index.Categories[1]
+	if web.Solved != 1 || web.Points != 200 || web.MaxPoints != 300 {
+		t.Errorf("got web totals %+v", web)
+	}
+
+	if web.Challenges[0].Name != "Cookies" || web.Challenges[0].Path != "web/200-Cookies/README.md" {
+		t.Errorf("expected challenges sorted by name with their path, got %+v", web.Challenges)
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	index := newTestIndex(t)
+
+	t.Run("md", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteIndex(index, "md", &buf); err != nil {
+			t.Fatalf("WriteIndex() returned error: %v", err)
+		}
+
+		want := "# Index\n\n## PWN\n\n- ✅ [Baby ROP](pwn/300-Baby-ROP/README.md)\n\n## WEB\n\n- ✅ [Cookies](web/200-Cookies/README.md)\n- ❌ [XSS me](web/100-XSS-me/README.md)\n\n"
+		if buf.String() != want {
+			t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteIndex(index, "json", &buf); err != nil {
+			t.Fatalf("WriteIndex() returned error: %v", err)
+		}
+
+		var decoded Index
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("failed to decode index: %v", err)
+		}
+
+		if !cmp.Equal(decoded.Categories, index.Categories) {
+			t.Errorf("got %+v, want %+v", decoded.Categories, index.Categories)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteIndex(index, "csv", &buf); err != nil {
+			t.Fatalf("WriteIndex() returned error: %v", err)
+		}
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to read CSV: %v", err)
+		}
+
+		want := [][]string{
+			{"id", "name", "category", "value", "solves", "solved", "tags", "path"},
+			{"1", "Baby ROP", "pwn", "300", "4", "true", "easy", "pwn/300-Baby-ROP/README.md"},
+			{"3", "Cookies", "web", "200", "10", "true", "", "web/200-Cookies/README.md"},
+			{"2", "XSS me", "web", "100", "40", "false", "", "web/100-XSS-me/README.md"},
+		}
+		if !cmp.Equal(records, want) {
+			t.Errorf("got %v, want %v", records, want)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteIndex(index, "html", &buf); err != nil {
+			t.Fatalf("WriteIndex() returned error: %v", err)
+		}
+
+		for _, want := range []string{`<a href="pwn/300-Baby-ROP/README.md">Baby ROP</a>`, "2/3 challenges solved, 500 points", `<td>web</td><td class="num">1</td>`} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("expected HTML to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if err := WriteIndex(index, "pdf", &bytes.Buffer{}); err == nil {
+			t.Error("expected an error for an unknown format")
+		}
+	})
+}
+
+func TestGenerateIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := GenerateIndex(indexChallenges, nil, dir, "md", "json", "csv", "html"); err != nil {
+		t.Fatalf("GenerateIndex() returned error: %v", err)
+	}
+
+	for _, format := range IndexFormats {
+		if _, err := os.Stat(path.Join(dir, "index."+format)); err != nil {
+			t.Errorf("expected index.%s: %v", format, err)
+		}
+	}
+
+	index, err := os.ReadFile(path.Join(dir, "index.md"))
+	if err != nil {
+		t.Fatalf("error reading index: %v", err)
+	}
+
+	if !strings.Contains(string(index), "- ✅ [Baby ROP](binary-exploitation/Baby-ROP/README.md)") {
+		t.Errorf("expected the default layout, got:\n%s", index)
+	}
+}
+
+func TestCSVField(t *testing.T) {
+	tests := []struct {
+		description string
+		field       string
+		want        string
+	}{
+		{"plain", "Baby ROP", "Baby ROP"},
+		{"empty", "", ""},
+		{"formula", "=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"plus", "+1", "'+1"},
+		{"minus", "-2+3", "'-2+3"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"tab", "\t=1", "'\t=1"},
+		{"inner equals", "a=b", "a=b"},
+	}
+
+	for _, test := range tests {
+		if got := CSVField(test.field); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.description, got, test.want)
+		}
+	}
+}
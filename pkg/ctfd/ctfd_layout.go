@@ -0,0 +1,285 @@
+package ctfd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ritchies/ctftool/internal/lib"
+)
+
+const (
+	// DefaultPathTemplate is the challenge folder used when no template is configured.
+	DefaultPathTemplate = "{{.Category}}/{{.Name}}"
+	// LegacyPathTemplate is the layout of workspaces created before path templates existed.
+	LegacyPathTemplate = "{{.CategoryWord}}/{{.Name}}"
+)
+
+// Layout decides where the folder of every challenge lives in the output directory.
+type Layout struct {
+	source   string
+	template *template.Template
+	// Aliases map raw category names (case-insensitive) to the category to use instead.
+	Aliases map[string]string
+	// assigned are the folders given to challenges by earlier runs, they never move
+	assigned map[int64]string
+}
+
+// PathData is the data available to path templates.
+type PathData struct {
+	ID           int64
+	Name         string // slug of the challenge name
+	RawName      string
+	Category     string // slug of the category, after aliases are applied
+	CategoryWord string // slug of the first word of the category, the layout of older versions
+	RawCategory  string
+	Value        int64
+}
+
+// NewLayout returns a layout using the path template and category aliases.
+// An empty template uses DefaultPathTemplate.
+//
+//	layout, err := NewLayout("{{.Category}}/{{.Value}}-{{.Name}}", map[string]string{"Binary Exploitation": "pwn"})
+//	if err != nil {
+//		fmt.Println(err)
+//	}
+func NewLayout(pathTemplate string, aliases map[string]string) (*Layout, error) {
+	if strings.TrimSpace(pathTemplate) == "" {
+		pathTemplate = DefaultPathTemplate
+	}
+
+	tmpl, err := template.New("path").Option("missingkey=error").Parse(pathTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template: %v", err)
+	}
+
+	normalized := make(map[string]string, len(aliases))
+	for category, alias := range aliases {
+		normalized[strings.ToLower(strings.TrimSpace(category))] = alias
+	}
+
+	layout := &Layout{source: pathTemplate, template: tmpl, Aliases: normalized}
+
+	// catch templates using unknown fields before any challenge is processed
+	if _, err := layout.path(ChallengesData{ID: 1, Name: "name", Category: "category"}); err != nil {
+		return nil, err
+	}
+
+	return layout, nil
+}
+
+// Category returns the slugged category of a challenge, after aliases are applied.
+func (l *Layout) Category(challenge ChallengesData) string {
+	category := challenge.Category
+	if l != nil {
+		if alias, ok := l.Aliases[strings.ToLower(strings.TrimSpace(category))]; ok {
+			category = alias
+		}
+	}
+
+	if category = lib.CleanSlug(category, true); category == "" {
+		return "uncategorized"
+	}
+
+	return category
+}
+
+// path renders the template for a challenge into a relative, slash separated path.
+func (l *Layout) path(challenge ChallengesData) (string, error) {
+	data := PathData{
+		ID:           challenge.ID,
+		Name:         lib.CleanSlug(challenge.Name, false),
+		RawName:      challenge.Name,
+		Category:     l.Category(challenge),
+		CategoryWord: lib.CleanSlug(strings.Split(challenge.Category, " ")[0], true),
+		RawCategory:  challenge.Category,
+		Value:        challenge.Value,
+	}
+
+	if data.Name == "" {
+		return "", fmt.Errorf("invalid name for challenge %d", challenge.ID)
+	}
+
+	if data.CategoryWord == "" {
+		data.CategoryWord = "uncategorized"
+	}
+
+	var buf bytes.Buffer
+	if err := l.template.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid path template: %v", err)
+	}
+
+	// drop empty segments, such as an empty category
+	var segments []string
+	for _, segment := range strings.Split(buf.String(), "/") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" || segment == "." {
+			continue
+		}
+		if segment == ".." {
+			return "", fmt.Errorf("path template must stay inside the output directory, got %q", buf.String())
+		}
+		segments = append(segments, segment)
+	}
+
+	if len(segments) == 0 {
+		return "", fmt.Errorf("empty path for challenge %d", challenge.ID)
+	}
+
+	return path.Join(segments...), nil
+}
+
+// Template returns the path template of the layout.
+func (l *Layout) Template() string {
+	return l.source
+}
+
+// Paths returns the relative folder of every challenge, keyed by challenge ID.
+// Challenges whose path can't be rendered are left out. Folders assigned by
+// earlier runs are kept, even if the challenge would render elsewhere now.
+// When a new challenge gets a folder that is already used, compared without
+// case for case-insensitive filesystems, its ID is appended, then a counter
+// until the folder is free. New challenges are assigned by increasing ID, so
+// the result only depends on the challenges and not on their order.
+func (l *Layout) Paths(challenges []ChallengesData) map[int64]string {
+	sorted := make([]ChallengesData, len(challenges))
+	copy(sorted, challenges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	paths := make(map[int64]string, len(sorted))
+	// used holds the lower cased paths, case-insensitive filesystems would merge them
+	used := make(map[string]bool, len(sorted)+len(l.assigned))
+
+	// assigned folders stay reserved, even for challenges that are hidden again
+	for _, assigned := range l.assigned {
+		used[strings.ToLower(assigned)] = true
+	}
+
+	for _, challenge := range sorted {
+		if assigned, ok := l.assigned[challenge.ID]; ok {
+			paths[challenge.ID] = assigned
+			continue
+		}
+
+		challengePath, err := l.path(challenge)
+		if err != nil {
+			continue
+		}
+
+		base := challengePath
+		for i := 1; used[strings.ToLower(challengePath)]; i++ {
+			challengePath = base + "-" + strconv.FormatInt(challenge.ID, 10)
+			if i > 1 {
+				challengePath += "-" + strconv.Itoa(i)
+			}
+		}
+
+		used[strings.ToLower(challengePath)] = true
+		paths[challenge.ID] = challengePath
+	}
+
+	return paths
+}
+
+// ManifestFile is the file of the output directory that records its layout.
+const ManifestFile = ".ctftool-layout.json"
+
+// Manifest records the path template of a workspace and the folder given to
+// every challenge, so later runs never move a folder with its files and writeup.
+type Manifest struct {
+	Template string           `json:"template"`
+	Paths    map[int64]string `json:"paths"`
+}
+
+// OpenLayout returns the layout of the workspace in outputPath. Without a path
+// template, the template recorded in the manifest of the workspace is used,
+// then LegacyPathTemplate for workspaces created by older versions, which have
+// an index.md but no manifest, and DefaultPathTemplate for new workspaces.
+// The folders recorded in the manifest are kept as long as the template is.
+//
+//	layout, err := OpenLayout("ctf", "", nil)
+//	if err != nil {
+//		fmt.Println(err)
+//	}
+func OpenLayout(outputPath string, pathTemplate string, aliases map[string]string) (*Layout, error) {
+	manifest, err := readManifest(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(pathTemplate) == "" {
+		switch {
+		case manifest != nil:
+			pathTemplate = manifest.Template
+		case fileExists(path.Join(outputPath, "index.md")):
+			pathTemplate = LegacyPathTemplate
+		}
+	}
+
+	layout, err := NewLayout(pathTemplate, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest != nil && manifest.Template == layout.source {
+		layout.assigned = manifest.Paths
+	}
+
+	return layout, nil
+}
+
+// Save records the folders of the challenges in the manifest of the workspace,
+// along with the folders assigned by earlier runs.
+func (l *Layout) Save(outputPath string, challenges []ChallengesData) error {
+	manifest := Manifest{Template: l.source, Paths: make(map[int64]string)}
+	for id, assigned := range l.assigned {
+		manifest.Paths[id] = assigned
+	}
+	for id, challengePath := range l.Paths(challenges) {
+		manifest.Paths[id] = challengePath
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path.Join(outputPath, ManifestFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write layout manifest: %v", err)
+	}
+
+	l.assigned = manifest.Paths
+	return nil
+}
+
+// readManifest reads the manifest of the workspace, nil when there is none.
+func readManifest(outputPath string) (*Manifest, error) {
+	data, err := os.ReadFile(path.Join(outputPath, ManifestFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	manifest := new(Manifest)
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid layout manifest %q: %v", ManifestFile, err)
+	}
+
+	return manifest, nil
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,180 @@
+package ctfd
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLayoutPaths(t *testing.T) {
+	challenges := []ChallengesData{
+		{ID: 4, Name: "Baby ROP", Category: "Binary Exploitation", Value: 300},
+		{ID: 2, Name: "XSS me", Category: "Web Exploitation", Value: 100},
+		{ID: 3, Name: "Baby ROP!", Category: "Binary Exploitation", Value: 500},
+		{ID: 5, Name: "Cookies", Category: "Web 2.0", Value: 200},
+		{ID: 6, Name: "???", Category: "Misc", Value: 50},
+	}
+
+	tests := []struct {
+		description string
+		template    string
+		aliases     map[string]string
+		want        map[int64]string
+	}{
+		{
+			"default layout",
+			"",
+			nil,
+			map[int64]string{
+				2: "web-exploitation/XSS-me",
+				3: "binary-exploitation/Baby-ROP",
+				4: "binary-exploitation/Baby-ROP-4",
+				5: "web-2-0/Cookies",
+			},
+		},
+		{
+			"value prefix and aliases",
+			"{{.Category}}/{{.Value}}-{{.Name}}",
+			map[string]string{"binary exploitation": "pwn", "Web Exploitation": "web", "Web 2.0": "web"},
+			map[int64]string{
+				2: "web/100-XSS-me",
+				3: "pwn/500-Baby-ROP",
+				4: "pwn/300-Baby-ROP",
+				5: "web/200-Cookies",
+			},
+		},
+		{
+			"layout of older versions",
+			"{{.CategoryWord}}/{{.Name}}",
+			nil,
+			map[int64]string{
+				2: "web/XSS-me",
+				3: "binary/Baby-ROP",
+				4: "binary/Baby-ROP-4",
+				5: "web/Cookies",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			layout, err := NewLayout(test.template, test.aliases)
+			if err != nil {
+				t.Fatalf("NewLayout() returned error: %v", err)
+			}
+
+			got := layout.Paths(challenges)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+
+			// the paths don't depend on the order of the challenges
+			reversed := make([]ChallengesData, len(challenges))
+			for i, challenge := range challenges {
+				reversed[len(challenges)-1-i] = challenge
+			}
+
+			if !cmp.Equal(layout.Paths(reversed), got) {
+				t.Error("expected the same paths for reversed challenges")
+			}
+		})
+	}
+}
+
+func TestNewLayoutErrors(t *testing.T) {
+	for _, template := range []string{"{{.Category", "{{.Unknown}}/{{.Name}}", "../{{.Name}}"} {
+		if _, err := NewLayout(template, nil); err == nil {
+			t.Errorf("expected an error for template %q", template)
+		}
+	}
+}
+
+func TestLayoutPathsCollisions(t *testing.T) {
+	layout, err := NewLayout("web/{{.Name}}", nil)
+	if err != nil {
+		t.Fatalf("NewLayout() returned error: %v", err)
+	}
+
+	// the suffixed path of "Foo" is the path of "foo 12"
+	got := layout.Paths([]ChallengesData{
+		{ID: 5, Name: "foo 12"},
+		{ID: 10, Name: "foo"},
+		{ID: 12, Name: "Foo"},
+	})
+
+	want := map[int64]string{5: "web/foo-12", 10: "web/foo", 12: "web/Foo-12-2"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestOpenLayout(t *testing.T) {
+	dir := t.TempDir()
+
+	layout, err := OpenLayout(dir, "", nil)
+	if err != nil {
+		t.Fatalf("OpenLayout() returned error: %v", err)
+	}
+	if layout.Template() != DefaultPathTemplate {
+		t.Errorf("got template %q for a new workspace", layout.Template())
+	}
+
+	challenges := []ChallengesData{{ID: 20, Name: "Baby ROP", Category: "pwn", Value: 500}}
+	if err := layout.Save(dir, challenges); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	// a hidden challenge with a lower ID is released and the value decays
+	challenges = []ChallengesData{
+		{ID: 7, Name: "baby-rop", Category: "pwn", Value: 100},
+		{ID: 20, Name: "Baby ROP", Category: "pwn", Value: 450},
+	}
+
+	layout, err = OpenLayout(dir, "", nil)
+	if err != nil {
+		t.Fatalf("OpenLayout() returned error: %v", err)
+	}
+
+	want := map[int64]string{7: "pwn/baby-rop-7", 20: "pwn/Baby-ROP"}
+	if got := layout.Paths(challenges); !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// another template starts a new layout
+	layout, err = OpenLayout(dir, "{{.Value}}-{{.Name}}", nil)
+	if err != nil {
+		t.Fatalf("OpenLayout() returned error: %v", err)
+	}
+
+	want = map[int64]string{7: "100-baby-rop", 20: "450-Baby-ROP"}
+	if got := layout.Paths(challenges); !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestOpenLayoutLegacyWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(path.Join(dir, "index.md"), []byte("# Index\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	layout, err := OpenLayout(dir, "", nil)
+	if err != nil {
+		t.Fatalf("OpenLayout() returned error: %v", err)
+	}
+
+	if layout.Template() != LegacyPathTemplate {
+		t.Errorf("got template %q, want the layout of older versions", layout.Template())
+	}
+
+	layout, err = OpenLayout(dir, DefaultPathTemplate, nil)
+	if err != nil {
+		t.Fatalf("OpenLayout() returned error: %v", err)
+	}
+
+	if layout.Template() != DefaultPathTemplate {
+		t.Errorf("expected --path-template to win, got %q", layout.Template())
+	}
+}
@@ -16,6 +16,22 @@ type CTFOpts struct {
 	Watch         bool
 	WatchInterval time.Duration
 	MaxFileSize   int64
+	PathTemplate  string
+	IndexFormats  []string
+	ReportJSON    bool
+
+	// Concurrency limits
+	Concurrency     int
+	FileConcurrency int
+
+	// Challenge filters
+	Categories []string
+	IDs        []int64
+	NameRegex  string
+	Values     string
+	Solves     string
+	Tags       []string
+	DryRun     bool
 }
 
 // NewOptions returns a new Options struct
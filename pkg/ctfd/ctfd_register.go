@@ -0,0 +1,232 @@
+package ctfd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ritchies/ctftool/pkg/scraper"
+)
+
+// Registration holds the details of a user account to register.
+type Registration struct {
+	Name             string
+	Email            string
+	Password         string
+	RegistrationCode string
+	// Fields are the custom user fields of the instance, keyed by their label
+	// (e.g. "Discord") or their form name (e.g. "fields[1]").
+	Fields map[string]string
+}
+
+// TeamCredentials holds the name and password of a team.
+type TeamCredentials struct {
+	Name     string
+	Password string
+}
+
+// Register creates a new user account using the registration form of the
+// instance. On success, the client is logged in as the new user.
+func Register(registration Registration) error {
+	registerURL, err := joinPath(client.BaseURL.String(), "register")
+	if err != nil {
+		return err
+	}
+
+	fields, err := customFields(registerURL.String(), registration.Fields)
+	if err != nil {
+		return err
+	}
+
+	setValues := func(values url.Values) {
+		values.Set("name", registration.Name)
+		values.Set("email", registration.Email)
+		values.Set("password", registration.Password)
+
+		if registration.RegistrationCode != "" {
+			values.Set("registration_code", registration.RegistrationCode)
+		}
+
+		for name, value := range fields {
+			values.Set(name, value)
+		}
+	}
+
+	resp, err := scraper.FetchAndSubmitFormWithOptions(client.Client, registerURL.String(), setValues, &scraper.FormOptions{
+		Field: "email",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to register: %s", resp.Status)
+	}
+
+	return checkAlerts(resp, "failed to register")
+}
+
+// CreateTeam creates a new team and joins it. The client has to be logged in.
+func CreateTeam(team TeamCredentials) error {
+	return submitTeamForm("teams/new", team, "failed to create team")
+}
+
+// JoinTeam joins an existing team using its password. The client has to be logged in.
+func JoinTeam(team TeamCredentials) error {
+	return submitTeamForm("teams/join", team, "failed to join team")
+}
+
+func submitTeamForm(page string, team TeamCredentials, message string) error {
+	teamURL, err := joinPath(client.BaseURL.String(), page)
+	if err != nil {
+		return err
+	}
+
+	setValues := func(values url.Values) {
+		values.Set("name", team.Name)
+		values.Set("password", team.Password)
+	}
+
+	resp, err := scraper.FetchAndSubmitFormWithOptions(client.Client, teamURL.String(), setValues, &scraper.FormOptions{
+		Field: "password",
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %v", message, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", message, resp.Status)
+	}
+
+	// CTFd sends users that are not logged in back to the login page
+	if strings.HasSuffix(resp.Request.URL.Path, "/login") {
+		return fmt.Errorf("%s: not logged in", message)
+	}
+
+	return checkAlerts(resp, message)
+}
+
+// customFields maps the labels of custom user fields to their form names.
+// Fields that are already given by form name are kept as is.
+func customFields(registerURL string, fields map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string)
+	if len(fields) == 0 {
+		return resolved, nil
+	}
+
+	var labels map[string]string
+	for key, value := range fields {
+		if strings.HasPrefix(key, "fields[") {
+			resolved[key] = value
+			continue
+		}
+
+		if labels == nil {
+			var err error
+			if labels, err = fieldLabels(registerURL); err != nil {
+				return nil, err
+			}
+		}
+
+		name, ok := labels[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			return nil, fmt.Errorf("unknown registration field %q", key)
+		}
+		resolved[name] = value
+	}
+
+	return resolved, nil
+}
+
+// fieldLabels returns the form names of the custom user fields of the
+// registration page, keyed by their lower cased label.
+func fieldLabels(registerURL string) (map[string]string, error) {
+	doc, err := client.GetDoc(registerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration page: %v", err)
+	}
+
+	labels := make(map[string]string)
+	doc.Find("label[for]").Each(func(_ int, s *goquery.Selection) {
+		name, _ := s.Attr("for")
+		if !strings.HasPrefix(name, "fields[") {
+			return
+		}
+
+		// drop the "*" CTFd appends to required fields
+		label := strings.TrimSuffix(strings.TrimSpace(s.Text()), "*")
+		labels[strings.ToLower(strings.TrimSpace(label))] = name
+	})
+
+	return labels, nil
+}
+
+// ReadTeammates reads registrations from a CSV file with a header row. The
+// name, email and password columns are required, a registration_code column
+// is optional and every other column is used as a custom user field.
+//
+//	name,email,password,Discord
+//	alice,alice@example.com,hunter2,alice#1337
+func ReadTeammates(r io.Reader) ([]Registration, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %v", err)
+	}
+
+	if len(records) < 2 {
+		return nil, errors.New("CSV needs a header row and at least one teammate")
+	}
+
+	header := records[0]
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	for _, required := range []string{"name", "email", "password"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing the %q column", required)
+		}
+	}
+
+	var registrations []Registration
+	for line, record := range records[1:] {
+		registration := Registration{Fields: make(map[string]string)}
+
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			switch strings.ToLower(strings.TrimSpace(header[i])) {
+			case "name":
+				registration.Name = value
+			case "email":
+				registration.Email = value
+			case "password":
+				registration.Password = value
+			case "registration_code":
+				registration.RegistrationCode = value
+			default:
+				if value != "" {
+					registration.Fields[strings.TrimSpace(header[i])] = value
+				}
+			}
+		}
+
+		if registration.Name == "" || registration.Email == "" || registration.Password == "" {
+			return nil, fmt.Errorf("CSV line %d: name, email and password are required", line+2)
+		}
+
+		registrations = append(registrations, registration)
+	}
+
+	return registrations, nil
+}
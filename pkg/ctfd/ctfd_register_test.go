@@ -0,0 +1,174 @@
+package ctfd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const registerPage = `<html><body>
+<div class="alert alert-danger alert-dismissible text-center" role="alert">
+	<span>%s</span>
+</div>
+<form method="post" accept-charset="utf-8">
+	<input id="name" name="name" type="text">
+	<input id="email" name="email" type="email">
+	<input id="password" name="password" type="password">
+	<label for="fields[1]">Discord*</label>
+	<input id="fields[1]" name="fields[1]" type="text">
+	<input id="nonce" name="nonce" type="hidden" value="abc">
+	<input id="_submit" name="_submit" type="submit" value="Submit">
+</form>
+</body></html>`
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		description  string
+		registration Registration
+		alert        string
+		want         map[string]string
+		wantErr      bool
+	}{
+		{
+			"register with custom field label",
+			Registration{
+				Name:     "alice",
+				Email:    "alice@example.com",
+				Password: "hunter2",
+				Fields:   map[string]string{"Discord": "alice#1337"},
+			},
+			"",
+			map[string]string{"name": "alice", "email": "alice@example.com", "password": "hunter2", "fields[1]": "alice#1337"},
+			false,
+		},
+		{
+			"register with error alert",
+			Registration{Name: "alice", Email: "alice@example.com", Password: "hunter2"},
+			"That user name is already taken",
+			nil,
+			true,
+		},
+		{
+			"unknown custom field",
+			Registration{Name: "alice", Email: "alice@example.com", Password: "hunter2", Fields: map[string]string{"Twitter": "@alice"}},
+			"",
+			nil,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			_, mux, cleanup := setup()
+			defer cleanup()
+
+			got := make(map[string]string)
+			mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+				alert := ""
+				if r.Method == http.MethodPost {
+					if err := r.ParseForm(); err != nil {
+						t.Errorf("error parsing form: %v", err)
+					}
+					for key := range test.want {
+						got[key] = r.PostForm.Get(key)
+					}
+					alert = test.alert
+				}
+
+				page := registerPage
+				if alert == "" {
+					page = strings.Replace(page, "alert-danger", "alert-none", 1)
+				}
+				fmt.Fprintf(w, page, alert)
+			})
+
+			err := Register(test.registration)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Register() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			if test.alert != "" && !strings.Contains(err.Error(), test.alert) {
+				t.Errorf("expected error to contain %q, got %v", test.alert, err)
+			}
+
+			if test.want != nil && !cmp.Equal(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJoinTeam(t *testing.T) {
+	_, mux, cleanup := setup()
+	defer cleanup()
+
+	var name, password string
+	mux.HandleFunc("/teams/join", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			name = r.FormValue("name")
+			password = r.FormValue("password")
+			if password != "secret" {
+				fmt.Fprint(w, `<div class="alert alert-danger alert-dismissible text-center" role="alert"><span>That information is incorrect</span></div>`)
+				return
+			}
+			http.Redirect(w, r, "/challenges", http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, `<form method="post"><input name="name"><input name="password" type="password"></form>`)
+	})
+
+	mux.HandleFunc("/challenges", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html></html>`)
+	})
+
+	if err := JoinTeam(TeamCredentials{Name: "pwners", Password: "secret"}); err != nil {
+		t.Errorf("JoinTeam() returned error: %v", err)
+	}
+
+	if name != "pwners" || password != "secret" {
+		t.Errorf("got name %q and password %q", name, password)
+	}
+
+	err := JoinTeam(TeamCredentials{Name: "pwners", Password: "wrong"})
+	if err == nil || !strings.Contains(err.Error(), "That information is incorrect") {
+		t.Errorf("expected alert error, got %v", err)
+	}
+}
+
+func TestReadTeammates(t *testing.T) {
+	tests := []struct {
+		description string
+		csv         string
+		want        []Registration
+		wantErr     bool
+	}{
+		{
+			"teammates with custom field",
+			"name,email,password,Discord\nalice,alice@example.com,hunter2,alice#1337\nbob, bob@example.com, letmein,\n",
+			[]Registration{
+				{Name: "alice", Email: "alice@example.com", Password: "hunter2", Fields: map[string]string{"Discord": "alice#1337"}},
+				{Name: "bob", Email: "bob@example.com", Password: "letmein", Fields: map[string]string{}},
+			},
+			false,
+		},
+		{"missing column", "name,password\nalice,hunter2\n", nil, true},
+		{"missing value", "name,email,password\nalice,,hunter2\n", nil, true},
+		{"header only", "name,email,password\n", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := ReadTeammates(strings.NewReader(test.csv))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ReadTeammates() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
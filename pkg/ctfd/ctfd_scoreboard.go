@@ -3,6 +3,8 @@ package ctfd
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 )
 
@@ -86,3 +88,222 @@ func (d *TopTeamData) GetTeam(number int) (*Team, error) {
 		return nil, fmt.Errorf("invalid team number: %d", number)
 	}
 }
+
+// Score returns the points of the solves of the team.
+func (t Team) Score() int {
+	score := 0
+	for _, solve := range t.Solves {
+		score += solve.Value
+	}
+	return score
+}
+
+// Standing is a team and its place on the scoreboard.
+type Standing struct {
+	Rank int
+	Team
+}
+
+// Standings returns the teams of the scoreboard by rank, places without a
+// team are skipped.
+func (d *TopTeamData) Standings() []Standing {
+	var standings []Standing
+	for rank := 1; rank <= 10; rank++ {
+		team, _ := d.GetTeam(rank)
+		if team.ID == 0 {
+			continue
+		}
+		standings = append(standings, Standing{Rank: rank, Team: *team})
+	}
+	return standings
+}
+
+// TopStandings returns the first count teams of the scoreboard by rank, with
+// their solves and awards. Unlike ScoreboardTop it isn't limited to 10 teams.
+func TopStandings(count int) ([]Standing, error) {
+	var teams map[string]Team
+	if err := getData(fmt.Sprintf("api/v1/scoreboard/top/%d", count), "scoreboard", &teams); err != nil {
+		return nil, err
+	}
+
+	var standings []Standing
+	for key, team := range teams {
+		rank, err := strconv.Atoi(key)
+		if err != nil || team.ID == 0 {
+			continue
+		}
+		standings = append(standings, Standing{Rank: rank, Team: team})
+	}
+
+	sort.Slice(standings, func(i, j int) bool {
+		return standings[i].Rank < standings[j].Rank
+	})
+
+	return standings, nil
+}
+
+// MyTeam returns the account of the credentials with its solves and awards,
+// the team in team mode and the user otherwise. When the mode of the
+// instance is unknown, the solves of the user are returned.
+func MyTeam() (*Team, error) {
+	account := "users"
+	if serverVersion().UserMode == "teams" {
+		account = "teams"
+	}
+
+	team := new(Team)
+	if err := getData(fmt.Sprintf("api/v1/%s/me", account), "account", team); err != nil {
+		return nil, err
+	}
+
+	var solves []struct {
+		ChallengeID int64 `json:"challenge_id"`
+		Challenge   struct {
+			Value int `json:"value"`
+		} `json:"challenge"`
+		Date time.Time `json:"date"`
+	}
+	if err := getData(fmt.Sprintf("api/v1/%s/me/solves", account), "solves", &solves); err != nil {
+		return nil, err
+	}
+
+	for _, solve := range solves {
+		team.Solves = append(team.Solves, Solves{
+			ChallengeID: solve.ChallengeID,
+			AccountID:   team.ID,
+			Value:       solve.Challenge.Value,
+			Date:        solve.Date,
+		})
+	}
+
+	// awards are hidden on some instances, the score is then the solves only
+	var awards []struct {
+		Value int       `json:"value"`
+		Date  time.Time `json:"date"`
+	}
+	if err := getData(fmt.Sprintf("api/v1/%s/me/awards", account), "awards", &awards); err == nil {
+		for _, award := range awards {
+			team.Solves = append(team.Solves, Solves{AccountID: team.ID, Value: award.Value, Date: award.Date})
+		}
+	}
+
+	return team, nil
+}
+
+// ScorePoint is the score of a team after one of its solves or awards.
+type ScorePoint struct {
+	Date  time.Time `json:"date"`
+	Score int       `json:"score"`
+}
+
+// Timeline returns the cumulative score of the team after each of its
+// solves and awards, by date.
+func (t Team) Timeline() []ScorePoint {
+	solves := make([]Solves, len(t.Solves))
+	copy(solves, t.Solves)
+	sort.SliceStable(solves, func(i, j int) bool {
+		return solves[i].Date.Before(solves[j].Date)
+	})
+
+	var timeline []ScorePoint
+	score := 0
+	for _, solve := range solves {
+		score += solve.Value
+		timeline = append(timeline, ScorePoint{Date: solve.Date, Score: score})
+	}
+
+	return timeline
+}
+
+// Movement is a standing and its change since a previous scoreboard.
+type Movement struct {
+	Standing
+	PreviousRank  int // 0 when the team wasn't on the previous scoreboard
+	PreviousScore int
+}
+
+// Places returns the places gained since the previous scoreboard, negative
+// when places were lost.
+func (m Movement) Places() int {
+	if m.PreviousRank == 0 {
+		return 0
+	}
+	return m.PreviousRank - m.Rank
+}
+
+// Delta returns the points scored since the previous scoreboard.
+func (m Movement) Delta() int {
+	if m.PreviousRank == 0 {
+		return 0
+	}
+	return m.Score() - m.PreviousScore
+}
+
+// CompareStandings returns the movement of every current standing since the
+// previous standings, teams are matched by ID.
+func CompareStandings(previous, current []Standing) []Movement {
+	before := make(map[int]Standing)
+	for _, standing := range previous {
+		before[standing.ID] = standing
+	}
+
+	var movements []Movement
+	for _, standing := range current {
+		movement := Movement{Standing: standing}
+		if old, ok := before[standing.ID]; ok {
+			movement.PreviousRank = old.Rank
+			movement.PreviousScore = old.Score()
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements
+}
+
+// ScoreboardMember is a member of a team on the scoreboard, with their own score.
+type ScoreboardMember struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Score       int    `json:"score"`
+	BracketID   int    `json:"bracket_id"`
+	BracketName string `json:"bracket_name"`
+}
+
+// ScoreboardEntry is an account, a team in team mode or a user otherwise, on
+// the scoreboard. The bracket is empty on instances without brackets.
+type ScoreboardEntry struct {
+	Position    int                `json:"pos"`
+	AccountID   int                `json:"account_id"`
+	AccountType string             `json:"account_type"`
+	Name        string             `json:"name"`
+	Score       int                `json:"score"`
+	BracketID   int                `json:"bracket_id"`
+	BracketName string             `json:"bracket_name"`
+	Members     []ScoreboardMember `json:"members"`
+}
+
+// Scoreboard is a snapshot of the whole scoreboard.
+type Scoreboard struct {
+	Time      time.Time         `json:"time"`
+	Standings []ScoreboardEntry `json:"standings"`
+}
+
+// FullScoreboard returns the whole scoreboard, with the brackets of the
+// accounts and the members of the teams, every page of it is requested.
+func FullScoreboard() (*Scoreboard, error) {
+	scoreboard := &Scoreboard{Time: time.Now().UTC(), Standings: []ScoreboardEntry{}}
+
+	err := getPages("api/v1/scoreboard", "scoreboard", func(data json.RawMessage) error {
+		var entries []ScoreboardEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+		scoreboard.Standings = append(scoreboard.Standings, entries...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return scoreboard, nil
+}
@@ -1,8 +1,11 @@
 package ctfd
 
 import (
+	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestTopTeamData_GetTeam(t *testing.T) {
@@ -244,3 +247,160 @@ func TestTopTeamData_GetTeam(t *testing.T) {
 		})
 	}
 }
+
+func TestTopTeamData_Standings(t *testing.T) {
+	data := TopTeamData{
+		Num1: Team{ID: 7, Name: "first", Solves: []Solves{{Value: 100}, {Value: 250}}},
+		Num3: Team{ID: 3, Name: "third", Solves: []Solves{{Value: 50}}},
+	}
+
+	standings := data.Standings()
+	if len(standings) != 2 {
+		t.Fatalf("expected 2 standings, got %+v", standings)
+	}
+
+	if standings[0].Rank != 1 || standings[0].Name != "first" || standings[0].Score() != 350 {
+		t.Errorf("got %+v with score %d", standings[0], standings[0].Score())
+	}
+
+	if standings[1].Rank != 3 || standings[1].Score() != 50 {
+		t.Errorf("got %+v with score %d", standings[1], standings[1].Score())
+	}
+}
+
+func TestTopStandings(t *testing.T) {
+	_, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/api/v1/scoreboard/top/12", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "data": {
+			"11": {"id": 4, "name": "eleventh", "solves": []},
+			"2": {"id": 9, "name": "second", "solves": [{"value": 100, "date": "2023-01-01T10:00:00Z"}]},
+			"1": {"id": 7, "name": "first", "solves": [{"value": 300, "date": "2023-01-01T09:00:00Z"}]}
+		}}`))
+	})
+
+	standings, err := TopStandings(12)
+	if err != nil {
+		t.Fatalf("TopStandings() returned error: %v", err)
+	}
+
+	var got []string
+	for _, standing := range standings {
+		got = append(got, fmt.Sprintf("%d:%s:%d", standing.Rank, standing.Name, standing.Score()))
+	}
+
+	if want := []string{"1:first:300", "2:second:100", "11:eleventh:0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMyTeam(t *testing.T) {
+	_, mux, cleanup := setup()
+	defer cleanup()
+
+	// the mode of the instance is unknown, the user is used
+	mux.HandleFunc("/api/v1/users/me", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "data": {"id": 5, "name": "alice"}}`))
+	})
+	mux.HandleFunc("/api/v1/users/me/solves", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "data": [{"challenge_id": 3, "challenge": {"value": 200}, "date": "2023-01-01T12:00:00Z"}]}`))
+	})
+	mux.HandleFunc("/api/v1/users/me/awards", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "data": [{"value": 50, "date": "2023-01-01T11:00:00Z"}]}`))
+	})
+
+	team, err := MyTeam()
+	if err != nil {
+		t.Fatalf("MyTeam() returned error: %v", err)
+	}
+
+	if team.ID != 5 || team.Name != "alice" || team.Score() != 250 {
+		t.Errorf("got %+v with score %d", team, team.Score())
+	}
+}
+
+func TestTeam_Timeline(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2023, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+
+	team := Team{Solves: []Solves{
+		{Value: 300, Date: at(12)},
+		{Value: 100, Date: at(9)},
+		{Value: -50, Date: at(10)},
+	}}
+
+	want := []ScorePoint{{Date: at(9), Score: 100}, {Date: at(10), Score: 50}, {Date: at(12), Score: 350}}
+	if got := team.Timeline(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if team.Solves[0].Value != 300 {
+		t.Error("Timeline() reordered the solves of the team")
+	}
+}
+
+func TestCompareStandings(t *testing.T) {
+	previous := []Standing{
+		{Rank: 1, Team: Team{ID: 7, Name: "first", Solves: []Solves{{Value: 300}}}},
+		{Rank: 2, Team: Team{ID: 3, Name: "second", Solves: []Solves{{Value: 200}}}},
+	}
+	current := []Standing{
+		{Rank: 1, Team: Team{ID: 3, Name: "second", Solves: []Solves{{Value: 200}, {Value: 500}}}},
+		{Rank: 2, Team: Team{ID: 7, Name: "first", Solves: []Solves{{Value: 300}}}},
+		{Rank: 3, Team: Team{ID: 9, Name: "new", Solves: []Solves{{Value: 100}}}},
+	}
+
+	var got []string
+	for _, movement := range CompareStandings(previous, current) {
+		got = append(got, fmt.Sprintf("%s:%d:%d", movement.Name, movement.Places(), movement.Delta()))
+	}
+
+	if want := []string{"second:1:500", "first:-1:0", "new:0:0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFullScoreboard(t *testing.T) {
+	_, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/api/v1/scoreboard", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Write([]byte(`{"success": true, "meta": {"pagination": {"page": 1, "next": 2, "pages": 2}}, "data": [
+				{"pos": 1, "account_id": 7, "account_type": "team", "name": "pwners", "score": 500, "bracket_id": 1, "bracket_name": "Students",
+				 "members": [{"id": 1, "name": "alice", "score": 300}, {"id": 2, "name": "bob", "score": 200}]}
+			]}`))
+		case "2":
+			w.Write([]byte(`{"success": true, "meta": {"pagination": {"page": 2, "next": null, "pages": 2}}, "data": [
+				{"pos": 2, "account_id": 9, "account_type": "team", "name": "rookies", "score": 100, "bracket_id": null, "members": []}
+			]}`))
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+
+	scoreboard, err := FullScoreboard()
+	if err != nil {
+		t.Fatalf("FullScoreboard() returned error: %v", err)
+	}
+
+	if scoreboard.Time.IsZero() {
+		t.Error("the snapshot has no time")
+	}
+
+	if len(scoreboard.Standings) != 2 {
+		t.Fatalf("got %d standings, want 2", len(scoreboard.Standings))
+	}
+
+	first := scoreboard.Standings[0]
+	if first.Name != "pwners" || first.BracketName != "Students" || len(first.Members) != 2 || first.Members[1].Score != 200 {
+		t.Errorf("unexpected first standing %+v", first)
+	}
+
+	if second := scoreboard.Standings[1]; second.Position != 2 || second.BracketID != 0 {
+		t.Errorf("unexpected second standing %+v", second)
+	}
+}
@@ -0,0 +1,170 @@
+package ctfd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Award is an award of an account. Unlocking a hint is recorded as an award
+// of minus its cost.
+type Award struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Value    int64     `json:"value"`
+	Category string    `json:"category"`
+	Date     time.Time `json:"date"`
+}
+
+// HintCost reports whether the award is the cost of an unlocked hint.
+func (a Award) HintCost() bool {
+	return a.Value < 0
+}
+
+// Awards returns the awards of the account, the team in team mode and the
+// user otherwise. /api/v1/awards is restricted to admins on most instances,
+// the awards of the account of the credentials are requested instead.
+func Awards(account *Team) ([]Award, error) {
+	mode := serverVersion().UserMode
+
+	filter, path := "user_id", "users"
+	if mode == "teams" {
+		filter, path = "team_id", "teams"
+	}
+
+	var awards []Award
+
+	resp, err := getOnce(fmt.Sprintf("api/v1/awards?%s=%d", filter, account.ID))
+	if err == nil {
+		defer resp.Body.Close()
+
+		response := struct {
+			Success bool     `json:"success"`
+			Data    *[]Award `json:"data"`
+		}{Data: &awards}
+		if resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&response) == nil && response.Success {
+			return awards, nil
+		}
+	}
+
+	awards = nil
+	if err := getData(fmt.Sprintf("api/v1/%s/me/awards", path), "awards", &awards); err != nil {
+		return nil, err
+	}
+
+	return awards, nil
+}
+
+// CategoryStats is the progress of the account in a category.
+type CategoryStats struct {
+	Category  string `json:"category"`
+	Total     int    `json:"total"`
+	Solved    int    `json:"solved"`
+	Available int64  `json:"available"`
+	Earned    int64  `json:"earned"`
+}
+
+// Stats is the progress of the account on the board. Points are the current
+// values of the challenges, the score adds the awards and deducts the costs
+// of the unlocked hints.
+type Stats struct {
+	Categories []CategoryStats  `json:"categories"`
+	Total      int              `json:"total"`
+	Solved     int              `json:"solved"`
+	Available  int64            `json:"available"`
+	Earned     int64            `json:"earned"`
+	Share      float64          `json:"share"`
+	Awards     int64            `json:"awards"`
+	HintCosts  int64            `json:"hint_costs"`
+	Score      int64            `json:"score"`
+	Easiest    []ChallengesData `json:"easiest"`
+	Unsolved   []ChallengesData `json:"unsolved"`
+}
+
+// NewStats computes the stats of the account from the challenges, the solves
+// of the account and its awards. Easiest keeps that many of the challenges the
+// account hasn't solved but others have, by most solves, and Unsolved has the
+// challenges nobody has solved.
+func NewStats(challenges []ChallengesData, account *Team, awards []Award, easiest int) *Stats {
+	solved := make(map[int64]bool)
+	if account != nil {
+		for _, solve := range account.Solves {
+			if id, ok := solve.ChallengeID.(int64); ok {
+				solved[id] = true
+			}
+		}
+	}
+
+	stats := &Stats{Categories: []CategoryStats{}, Easiest: []ChallengesData{}, Unsolved: []ChallengesData{}}
+	categories := make(map[string]*CategoryStats)
+
+	var open []ChallengesData
+	for _, challenge := range challenges {
+		category, ok := categories[challenge.Category]
+		if !ok {
+			category = &CategoryStats{Category: challenge.Category}
+			categories[challenge.Category] = category
+		}
+
+		category.Total++
+		category.Available += challenge.Value
+
+		if challenge.SolvedByMe || solved[challenge.ID] {
+			category.Solved++
+			category.Earned += challenge.Value
+		} else if challenge.Solves > 0 {
+			open = append(open, challenge)
+		} else {
+			stats.Unsolved = append(stats.Unsolved, challenge)
+		}
+	}
+
+	for _, category := range categories {
+		stats.Categories = append(stats.Categories, *category)
+		stats.Total += category.Total
+		stats.Solved += category.Solved
+		stats.Available += category.Available
+		stats.Earned += category.Earned
+	}
+
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		return strings.ToLower(stats.Categories[i].Category) < strings.ToLower(stats.Categories[j].Category)
+	})
+
+	if stats.Available > 0 {
+		stats.Share = float64(stats.Earned) / float64(stats.Available)
+	}
+
+	for _, award := range awards {
+		if award.HintCost() {
+			stats.HintCosts -= award.Value
+		} else {
+			stats.Awards += award.Value
+		}
+	}
+	stats.Score = stats.Earned + stats.Awards - stats.HintCosts
+
+	// the most solved challenges first, then the cheapest
+	sort.SliceStable(open, func(i, j int) bool {
+		if open[i].Solves != open[j].Solves {
+			return open[i].Solves > open[j].Solves
+		}
+		return open[i].Value < open[j].Value
+	})
+	if easiest < 0 {
+		easiest = 0
+	}
+	if len(open) > easiest {
+		open = open[:easiest]
+	}
+	stats.Easiest = append(stats.Easiest, open...)
+
+	sort.SliceStable(stats.Unsolved, func(i, j int) bool {
+		return stats.Unsolved[i].Value < stats.Unsolved[j].Value
+	})
+
+	return stats
+}
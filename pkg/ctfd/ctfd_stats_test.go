@@ -0,0 +1,102 @@
+package ctfd
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAwards(t *testing.T) {
+	tests := []struct {
+		description string
+		admin       bool
+		want        []int64
+	}{
+		{description: "filtered award list", admin: true, want: []int64{100, -25}},
+		{description: "awards of the account without admin rights", admin: false, want: []int64{50}},
+	}
+
+	for _, test := range tests {
+		_, mux, cleanup := setup()
+
+		admin := test.admin
+		mux.HandleFunc("/api/v1/awards", func(w http.ResponseWriter, r *http.Request) {
+			if !admin {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.URL.Query().Get("user_id") != "5" {
+				t.Errorf("%s: got query %q, want the awards of user 5", test.description, r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"success": true, "data": [{"id": 1, "value": 100}, {"id": 2, "value": -25, "name": "Hint 3"}]}`))
+		})
+		mux.HandleFunc("/api/v1/users/me/awards", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"success": true, "data": [{"id": 3, "value": 50}]}`))
+		})
+
+		awards, err := Awards(&Team{ID: 5})
+		cleanup()
+		if err != nil {
+			t.Fatalf("%s: Awards() returned error: %v", test.description, err)
+		}
+
+		var got []int64
+		for _, award := range awards {
+			got = append(got, award.Value)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.description, got, test.want)
+		}
+	}
+}
+
+func TestNewStats(t *testing.T) {
+	challenges := []ChallengesData{
+		{ID: 1, Name: "warmup", Category: "web", Value: 100, Solves: 40, SolvedByMe: true},
+		{ID: 2, Name: "sqli", Category: "web", Value: 300, Solves: 12},
+		{ID: 3, Name: "heap", Category: "pwn", Value: 500, Solves: 0},
+		{ID: 4, Name: "rop", Category: "pwn", Value: 400, Solves: 12},
+		{ID: 5, Name: "rsa", Category: "Crypto", Value: 200, Solves: 3},
+	}
+	account := &Team{ID: 7, Solves: []Solves{{ChallengeID: int64(5), Value: 200}, {Value: 100}}}
+	awards := []Award{{Value: 100}, {Value: -25}, {Value: -10}}
+
+	stats := NewStats(challenges, account, awards, 2)
+
+	wantCategories := []CategoryStats{
+		{Category: "Crypto", Total: 1, Solved: 1, Available: 200, Earned: 200},
+		{Category: "pwn", Total: 2, Available: 900},
+		{Category: "web", Total: 2, Solved: 1, Available: 400, Earned: 100},
+	}
+	if !reflect.DeepEqual(stats.Categories, wantCategories) {
+		t.Errorf("got categories %+v, want %+v", stats.Categories, wantCategories)
+	}
+
+	if stats.Total != 5 || stats.Solved != 2 || stats.Available != 1500 || stats.Earned != 300 || stats.Share != 0.2 {
+		t.Errorf("got totals %+v", stats)
+	}
+
+	if stats.Awards != 100 || stats.HintCosts != 35 || stats.Score != 365 {
+		t.Errorf("got awards %d, hint costs %d and score %d, want 100, 35 and 365", stats.Awards, stats.HintCosts, stats.Score)
+	}
+
+	var easiest []int64
+	for _, challenge := range stats.Easiest {
+		easiest = append(easiest, challenge.ID)
+	}
+	if want := []int64{2, 4}; !reflect.DeepEqual(easiest, want) {
+		t.Errorf("got easiest %v, want %v", easiest, want)
+	}
+
+	if len(stats.Unsolved) != 1 || stats.Unsolved[0].ID != 3 {
+		t.Errorf("got unsolved %+v, want heap", stats.Unsolved)
+	}
+}
+
+func TestNewStats_NegativeEasiest(t *testing.T) {
+	challenges := []ChallengesData{{ID: 1, Name: "sqli", Category: "web", Value: 300, Solves: 12}}
+
+	if stats := NewStats(challenges, nil, nil, -1); len(stats.Easiest) != 0 {
+		t.Errorf("got easiest %+v, want none", stats.Easiest)
+	}
+}
@@ -0,0 +1,84 @@
+package ctfd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ServerVersion is the CTFd version fingerprinted from the pages of an instance.
+type ServerVersion struct {
+	Major    int    // major version, 0 when unknown
+	Version  string // detected version range, e.g. "2.x", "3.x" or ">= 3.6"
+	Theme    string // name of the active theme
+	UserMode string // "users" or "teams", empty when unknown
+}
+
+var (
+	themeRegex    = regexp.MustCompile(`/themes/([\w.-]+)/static/`)
+	userModeRegex = regexp.MustCompile(`(?:['"]?userMode['"]?\s*:|user_mode\s*=)\s*['"](\w+)['"]`)
+)
+
+// DetectVersion fingerprints the CTFd version of the instance from the assets
+// and inline scripts of its index page. The page is requested once, without
+// the retries of the client, so an unreachable page fails fast.
+func DetectVersion() (*ServerVersion, error) {
+	resp, err := getOnce("")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get %q: %s", resp.Request.URL, resp.Status)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return fingerprint(doc), nil
+}
+
+// fingerprintHTML returns the version of a CTFd page from its HTML.
+func fingerprintHTML(html []byte) (*ServerVersion, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil, err
+	}
+
+	return fingerprint(doc), nil
+}
+
+// fingerprint returns the version of a CTFd page.
+// CTFd 2 declares globals such as `var csrf_nonce`, CTFd 3 has a `window.init`
+// object and 3.6 replaced the core theme with core-beta.
+func fingerprint(doc *goquery.Document) *ServerVersion {
+	version := &ServerVersion{}
+
+	html, _ := doc.Html()
+
+	if match := themeRegex.FindStringSubmatch(html); len(match) == 2 {
+		version.Theme = match[1]
+	}
+
+	if match := userModeRegex.FindStringSubmatch(html); len(match) == 2 {
+		version.UserMode = match[1]
+	}
+
+	switch {
+	case version.Theme == "core-beta":
+		version.Major, version.Version = 3, ">= 3.6"
+	case strings.Contains(html, "window.init") || strings.Contains(html, "csrfNonce"):
+		version.Major, version.Version = 3, "3.x"
+	case strings.Contains(html, "csrf_nonce") || strings.Contains(html, "script_root"):
+		version.Major, version.Version = 2, "2.x"
+	}
+
+	return version
+}
@@ -0,0 +1,45 @@
+package ctfd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		description string
+		html        string
+		want        ServerVersion
+	}{
+		{"unknown", `<html><body>Hello</body></html>`, ServerVersion{}},
+		{
+			"ctfd 2",
+			`<html><script>var script_root = ""; var csrf_nonce = "abc"; var user_mode = "users";</script><script src="/themes/core/static/js/vendor.bundle.min.js"></script></html>`,
+			ServerVersion{Major: 2, Version: "2.x", Theme: "core", UserMode: "users"},
+		},
+		{
+			"ctfd 3",
+			`<html><script>window.init = {'urlRoot': "", 'csrfNonce': "abc", 'userMode': "teams"}</script><script src="/themes/core/static/js/core.min.js"></script></html>`,
+			ServerVersion{Major: 3, Version: "3.x", Theme: "core", UserMode: "teams"},
+		},
+		{
+			"ctfd 3.6 core-beta",
+			`<html><script>window.init = {'csrfNonce': "abc", 'userMode': "users"}</script><script src="/themes/core-beta/static/assets/index.js"></script></html>`,
+			ServerVersion{Major: 3, Version: ">= 3.6", Theme: "core-beta", UserMode: "users"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := fingerprintHTML([]byte(test.html))
+			if err != nil {
+				t.Fatalf("fingerprintHTML() returned error: %v", err)
+			}
+
+			if !cmp.Equal(*got, test.want) {
+				t.Errorf("got %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
@@ -16,6 +16,10 @@ const ctftimeURL = "https://ctftime.org/"
 
 var client = scraper.NewClient(nil)
 
+func NewClient() *scraper.Client {
+	return client
+}
+
 // Struct for API Endpoint ctftime.org/api/v1/events/
 type Event struct {
 	ID            uint64    `json:"id"`
@@ -0,0 +1,356 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotCached is returned in offline mode when a request has no cached response.
+var ErrNotCached = errors.New("response not cached")
+
+// DefaultMaxCacheBodySize is the largest response body stored by a new cache.
+const DefaultMaxCacheBodySize = 5000000
+
+// DefaultCacheMaxAge is how long a new cache keeps the entries that are not
+// used anymore.
+const DefaultCacheMaxAge = 7 * 24 * time.Hour
+
+// Cache stores GET responses on disk and revalidates them with
+// If-None-Match and If-Modified-Since once they are older than their TTL.
+// Only successful JSON responses of the API are stored, challenge files and
+// HTML pages always go to the network.
+type Cache struct {
+	// Dir is the directory the responses are stored in.
+	Dir string
+	// TTL is how long a response is served without revalidation. Zero means
+	// every request is revalidated.
+	TTL time.Duration
+	// TTLs overrides TTL for URL paths starting with the given prefix, the
+	// longest matching prefix wins.
+	TTLs map[string]time.Duration
+	// Offline serves cached responses only, regardless of their age, and
+	// never touches the network.
+	Offline bool
+	// MaxBodySize is the largest response body stored, larger responses are
+	// passed through without being buffered.
+	MaxBodySize int64
+	// MaxAge is how long Prune keeps an entry after it was stored or
+	// revalidated, at least the largest TTL.
+	MaxAge time.Duration
+	// Account returns a stable identity of the account a request is sent as,
+	// like the username of a password login, or an empty string when it is
+	// unknown. Session cookies change on every login, with an identity they
+	// are left out of the key so entries are shared across runs.
+	Account func(req *http.Request) string
+}
+
+// cacheEntry is a response as it is stored on disk.
+type cacheEntry struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Stored     time.Time   `json:"stored"`
+}
+
+// NewCache returns a cache storing its responses in dir.
+//
+//	cache := NewCache("/tmp/ctftool", time.Minute)
+//	err := client.Configure(&TransportOptions{Cache: cache})
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{
+		Dir:         dir,
+		TTL:         ttl,
+		TTLs:        make(map[string]time.Duration),
+		MaxBodySize: DefaultMaxCacheBodySize,
+		MaxAge:      DefaultCacheMaxAge,
+	}
+}
+
+// RoundTripper returns an http.RoundTripper that serves GET requests from the
+// cache and sends the others to next.
+func (c *Cache) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &cacheTransport{cache: c, tripper: next}
+}
+
+// ttlFor returns the TTL of the given URL path.
+func (c *Cache) ttlFor(urlPath string) time.Duration {
+	ttl := c.TTL
+	longest := -1
+
+	for prefix, prefixTTL := range c.TTLs {
+		if !strings.HasPrefix(prefix, "/") {
+			prefix = "/" + prefix
+		}
+
+		if strings.HasPrefix(urlPath, prefix) && len(prefix) > longest {
+			ttl = prefixTTL
+			longest = len(prefix)
+		}
+	}
+
+	return ttl
+}
+
+// key returns the file name of the cached response. Requests of different
+// accounts are cached separately, responses like solved_by_me depend on the
+// account. Without an identity from Account, the account is the token or the
+// session cookie, other cookies like cf_clearance rotate and are ignored.
+func (c *Cache) key(req *http.Request) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.String() + "\n"))
+
+	account := ""
+	if c.Account != nil {
+		account = c.Account(req)
+	}
+
+	if account != "" {
+		hash.Write([]byte("account " + account))
+	} else {
+		hash.Write([]byte(req.Header.Get("Authorization") + "\n"))
+		if cookie, err := req.Cookie("session"); err == nil {
+			hash.Write([]byte(cookie.Value))
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)) + ".json"
+}
+
+// maxAge returns the age above which Prune removes an entry, MaxAge or the
+// largest TTL if it is longer.
+func (c *Cache) maxAge() time.Duration {
+	age := c.MaxAge
+	if c.TTL > age {
+		age = c.TTL
+	}
+	for _, prefixTTL := range c.TTLs {
+		if prefixTTL > age {
+			age = prefixTTL
+		}
+	}
+	return age
+}
+
+// Prune removes the entries older than maxAge, and the temporary files left
+// by interrupted writes, so entries of old sessions and instances don't pile
+// up. Offline caches are never pruned, stale entries are all they serve.
+func (c *Cache) Prune() error {
+	if c.Offline {
+		return nil
+	}
+
+	entries, err := os.ReadDir(c.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	maxAge := c.maxAge()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".tmp")) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		// the modification time is the time the entry was stored or revalidated
+		if time.Since(info.ModTime()) > maxAge {
+			if err := os.Remove(filepath.Join(c.Dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// cacheableRequest reports whether the request goes through the cache, only
+// API requests do.
+func cacheableRequest(req *http.Request) bool {
+	return req.Method == http.MethodGet && strings.Contains(req.URL.Path, "/api/")
+}
+
+// cacheableResponse reports whether the response can be stored. HTML pages carry
+// session bound CSRF nonces, and downloads can be larger than the memory.
+func (c *Cache) cacheableResponse(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusOK &&
+		strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") &&
+		resp.Header.Get("Content-Disposition") == "" &&
+		resp.ContentLength <= c.MaxBodySize
+}
+
+func (c *Cache) load(req *http.Request) (*cacheEntry, error) {
+	b, err := os.ReadFile(filepath.Join(c.Dir, c.key(req)))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := new(cacheEntry)
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (c *Cache) store(req *http.Request, entry *cacheEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so concurrent readers never see a partial entry
+	file := filepath.Join(c.Dir, c.key(req))
+	tmp := fmt.Sprintf("%s.%d.tmp", file, time.Now().UnixNano())
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, file)
+}
+
+// response builds an http.Response from a cache entry.
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("X-Ctftool-Cache", e.Stored.Format(time.RFC3339))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheTransport is the http.RoundTripper returned by Cache.RoundTripper.
+type cacheTransport struct {
+	cache   *Cache
+	tripper http.RoundTripper
+}
+
+// RoundTrip is an implementation of the http.RoundTripper interface.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		if t.cache.Offline {
+			return nil, fmt.Errorf("%s %s: cannot send requests in offline mode", req.Method, req.URL)
+		}
+		return t.tripper.RoundTrip(req)
+	}
+
+	if !cacheableRequest(req) {
+		if t.cache.Offline {
+			return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL, ErrNotCached)
+		}
+		return t.tripper.RoundTrip(req)
+	}
+
+	entry, err := t.cache.load(req)
+	if err != nil {
+		entry = nil
+	}
+
+	if t.cache.Offline {
+		if entry == nil {
+			return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL, ErrNotCached)
+		}
+		return entry.response(req), nil
+	}
+
+	if entry != nil {
+		if time.Since(entry.Stored) < t.cache.ttlFor(req.URL.Path) {
+			return entry.response(req), nil
+		}
+
+		// revalidate the cached response, without changing the caller's request
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" && req.Header.Get("If-None-Match") == "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := entry.Header.Get("Last-Modified"); modified != "" && req.Header.Get("If-Modified-Since") == "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := t.tripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+
+		entry.Stored = time.Now()
+		_ = t.cache.store(req, entry)
+
+		return entry.response(req), nil
+	}
+
+	if !t.cache.cacheableResponse(resp) {
+		return resp, nil
+	}
+
+	// the length can be unknown, never read more than the limit
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.cache.MaxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if int64(len(body)) > t.cache.MaxBodySize {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// cookies are never replayed from the cache, they would overwrite the current session
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	// a failing cache should never fail the request
+	_ = t.cache.store(req, &cacheEntry{
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     header,
+		Body:       body,
+		Stored:     time.Now(),
+	})
+
+	return resp, nil
+}
+
+// readCloser reads from a reader and closes the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,285 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Cache(t *testing.T) {
+	var hits, notModified int
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/api/v1/challenges", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"success": true}`)
+	})
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<form></form>`)
+	})
+
+	cache := NewCache(t.TempDir(), 0)
+	cache.TTLs["/api/v1/challenges"] = time.Hour
+
+	client := NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	if err := client.Configure(&TransportOptions{Cache: cache}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	get := func(path string) string {
+		t.Helper()
+
+		resp, err := client.GetFile(path)
+		if err != nil {
+			t.Fatalf("GetFile(%q) returned error: %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	// fresh responses are served from the cache
+	for i := 0; i < 3; i++ {
+		if body := get("api/v1/challenges"); body != `{"success": true}` {
+			t.Errorf("unexpected body %q", body)
+		}
+	}
+
+	if hits != 1 {
+		t.Errorf("expected 1 request to the server, got %d", hits)
+	}
+
+	// stale responses are revalidated with If-None-Match
+	cache.TTLs["/api/v1/challenges"] = 0
+	if body := get("api/v1/challenges"); body != `{"success": true}` {
+		t.Errorf("unexpected body after revalidation %q", body)
+	}
+
+	if hits != 2 || notModified != 1 {
+		t.Errorf("expected a conditional request, got %d requests and %d not modified", hits, notModified)
+	}
+
+	// html pages are never cached
+	get("login")
+	get("login")
+	if hits != 4 {
+		t.Errorf("expected html pages to skip the cache, got %d requests", hits)
+	}
+
+	// offline mode serves the cache only
+	cache.Offline = true
+	if body := get("api/v1/challenges"); body != `{"success": true}` {
+		t.Errorf("unexpected body in offline mode %q", body)
+	}
+
+	if hits != 4 {
+		t.Errorf("expected no request in offline mode, got %d requests", hits)
+	}
+
+	if _, err := client.Client.Get(server.URL + "/login"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("expected ErrNotCached in offline mode, got %v", err)
+	}
+}
+
+func Test_Cache_ttlFor(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Minute)
+	cache.TTLs["/api/v1/challenges"] = time.Hour
+	cache.TTLs["api/v1/challenges/attempt"] = 0
+
+	tests := []struct {
+		path string
+		want time.Duration
+	}{
+		{"/api/v1/scoreboard", time.Minute},
+		{"/api/v1/challenges", time.Hour},
+		{"/api/v1/challenges/12", time.Hour},
+		{"/api/v1/challenges/attempt", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := cache.ttlFor(tt.path); got != tt.want {
+				t.Errorf("ttlFor(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Cache_skipsDownloads(t *testing.T) {
+	var hits int
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	large := strings.Repeat("a", 100)
+
+	mux.HandleFunc("/files/flag.bin", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/octet-stream")
+		fmt.Fprint(w, "binary")
+	})
+	mux.HandleFunc("/api/v1/export", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="export.json"`)
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/api/v1/large", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		// flushing first sends the body chunked, with an unknown length
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, large)
+	})
+
+	dir := t.TempDir()
+	cache := NewCache(dir, time.Hour)
+	cache.MaxBodySize = 10
+
+	client := NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	if err := client.Configure(&TransportOptions{Cache: cache}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	for _, tt := range []struct{ path, want string }{
+		{"files/flag.bin", "binary"},
+		{"api/v1/export", "{}"},
+		{"api/v1/large", large},
+	} {
+		for i := 0; i < 2; i++ {
+			resp, err := client.Client.Get(server.URL + "/" + tt.path)
+			if err != nil {
+				t.Fatalf("Get(%q) returned error: %v", tt.path, err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if string(body) != tt.want {
+				t.Errorf("got body %q for %s, want %q", body, tt.path, tt.want)
+			}
+		}
+	}
+
+	if hits != 6 {
+		t.Errorf("expected every request to reach the server, got %d requests", hits)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected nothing to be cached, got %d entries", len(entries))
+	}
+}
+
+func Test_Cache_key(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+
+	request := func(cookie string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "https://ctf.example/api/v1/challenges", nil)
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		return req
+	}
+
+	if cache.key(request("session=alice")) == cache.key(request("session=bob")) {
+		t.Error("expected accounts with different sessions to be cached separately")
+	}
+
+	if cache.key(request("session=alice")) != cache.key(request("session=alice")) {
+		t.Error("expected the same session to share the cache")
+	}
+}
+
+func Test_Cache_keyAccount(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+
+	request := func(cookie string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "https://ctf.example/api/v1/challenges", nil)
+		req.Header.Set("Cookie", cookie)
+		return req
+	}
+
+	if cache.key(request("session=alice; cf_clearance=one")) != cache.key(request("session=alice; cf_clearance=two")) {
+		t.Error("expected a rotated cf_clearance cookie to share the cache")
+	}
+
+	// a password login gets a new session on every run
+	cache.Account = func(req *http.Request) string { return "alice" }
+	if cache.key(request("session=first")) != cache.key(request("session=second")) {
+		t.Error("expected the sessions of the same account to share the cache")
+	}
+
+	other := NewCache(cache.Dir, time.Hour)
+	other.Account = func(req *http.Request) string { return "bob" }
+	if cache.key(request("session=first")) == other.key(request("session=first")) {
+		t.Error("expected different accounts to be cached separately")
+	}
+}
+
+func Test_Cache_Prune(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(dir, time.Minute)
+	cache.MaxAge = time.Hour
+	cache.TTLs["/api/v1/events"] = 2 * time.Hour
+
+	old := time.Now().Add(-3 * time.Hour)
+	recent := time.Now().Add(-90 * time.Minute)
+	for name, modified := range map[string]time.Time{"old.json": old, "recent.json": recent, "partial.json.1.tmp": old, "notes.txt": old} {
+		file := dir + "/" + name
+		if err := os.WriteFile(file, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := cache.Prune(); err != nil {
+		t.Fatalf("Prune() returned error: %v", err)
+	}
+
+	var names []string
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	// the largest TTL is longer than MaxAge, recent entries are kept
+	if want := "notes.txt recent.json"; strings.Join(names, " ") != want {
+		t.Errorf("got %v, want %s", names, want)
+	}
+
+	// offline caches only serve stale entries
+	cache.Offline = true
+	cache.MaxAge, cache.TTLs = 0, nil
+	if err := cache.Prune(); err != nil {
+		t.Fatalf("Prune() returned error: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("offline Prune() left %d entries, want 2", len(entries))
+	}
+}
@@ -0,0 +1,417 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"unicode/utf8"
+)
+
+// redacted replaces credentials and cookies in recorded interactions.
+const redacted = "REDACTED"
+
+// redactedHeaders are the headers whose values are never written to a cassette.
+var redactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Csrf-Token",
+}
+
+// redactedFormFields are the form fields whose values are never written to a cassette.
+var redactedFormFields = []string{"password", "confirm", "nonce"}
+
+// redactedQueryParams are the query parameters whose values are never written
+// to a cassette, such as the per-user token of CTFd file URLs.
+var redactedQueryParams = []string{"token"}
+
+// Interaction is a single recorded request and its response. A cassette file
+// holds one JSON encoded interaction per line.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the redacted request of an interaction.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response of an interaction. Bodies that are not
+// valid UTF-8 are stored base64 encoded in BodyBase64.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"body_base64,omitempty"`
+}
+
+// Cassette records HTTP interactions to a JSONL file, or replays them from one.
+// A single cassette can be shared by several clients.
+type Cassette struct {
+	mu           sync.Mutex
+	file         *os.File
+	replay       bool
+	interactions map[string][]Interaction
+	redact       []string // extra headers redacted on top of redactedHeaders
+}
+
+// NewRecordingCassette creates (or truncates) the file at path and returns a
+// cassette that records every request made through it.
+//
+//	cassette, err := NewRecordingCassette("session.jsonl")
+//	if err != nil {
+//		fmt.Println(err)
+//	}
+//	defer cassette.Close()
+func NewRecordingCassette(path string) (*Cassette, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %v", err)
+	}
+
+	return &Cassette{file: file}, nil
+}
+
+// LoadCassette reads the recorded interactions from the file at path and
+// returns a cassette that serves them without any network access.
+//
+//	cassette, err := LoadCassette("session.jsonl")
+//	if err != nil {
+//		fmt.Println(err)
+//	}
+func LoadCassette(path string) (*Cassette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %v", err)
+	}
+	defer file.Close()
+
+	cassette := &Cassette{
+		replay:       true,
+		interactions: make(map[string][]Interaction),
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 256*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette line %d: %v", line, err)
+		}
+
+		// cassettes recorded before the redaction of the query still match
+		key := interactionKey(interaction.Request.Method, redactRawURL(interaction.Request.URL))
+		cassette.interactions[key] = append(cassette.interactions[key], interaction)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %v", err)
+	}
+
+	return cassette, nil
+}
+
+// Close closes the cassette file of a recording cassette.
+func (c *Cassette) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// RedactHeaders adds headers whose values are never written to the cassette,
+// such as the custom headers of the client that carry access tokens.
+func (c *Cassette) RedactHeaders(names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.redact = append(c.redact, names...)
+}
+
+// RoundTripper returns an http.RoundTripper that records the interactions of
+// next, or replays them when the cassette was loaded with LoadCassette.
+func (c *Cassette) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &cassetteTransport{cassette: c, tripper: next}
+}
+
+// cassetteTransport is the http.RoundTripper returned by Cassette.RoundTripper.
+type cassetteTransport struct {
+	cassette *Cassette
+	tripper  http.RoundTripper
+}
+
+// RoundTrip is an implementation of the http.RoundTripper interface.
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cassette.replay {
+		return t.cassette.play(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.tripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	if err := t.cassette.record(req, reqBody, resp, respBody); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// record writes a redacted interaction to the cassette file.
+func (c *Cassette) record(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) error {
+	c.mu.Lock()
+	redact := append(append([]string{}, redactedHeaders...), c.redact...)
+	c.mu.Unlock()
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    redactURL(req.URL),
+			Header: redactHeader(req.Header, redact),
+			Body:   redactBody(req.Header.Get("Content-Type"), reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header, redact),
+		},
+	}
+
+	if utf8.Valid(respBody) {
+		interaction.Response.Body = string(respBody)
+	} else {
+		interaction.Response.BodyBase64 = base64.StdEncoding.EncodeToString(respBody)
+	}
+
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return fmt.Errorf("failed to encode interaction: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return fmt.Errorf("cassette is closed")
+	}
+
+	_, err = c.file.Write(append(line, '\n'))
+	return err
+}
+
+// play returns the next recorded response for the request. Responses for the
+// same method and URL are served in the order they were recorded, the last
+// one is repeated once the others are used up.
+func (c *Cassette) play(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := interactionKey(req.Method, redactURL(req.URL))
+	recorded := c.interactions[key]
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+	}
+
+	interaction := recorded[0]
+	if len(recorded) > 1 {
+		c.interactions[key] = recorded[1:]
+	}
+
+	body := []byte(interaction.Response.Body)
+	if interaction.Response.BodyBase64 != "" {
+		var err error
+		body, err = base64.StdEncoding.DecodeString(interaction.Response.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode recorded body: %v", err)
+		}
+	}
+
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func interactionKey(method, url string) string {
+	return method + " " + url
+}
+
+// redactURL returns the URL with the values of the credential query
+// parameters replaced. Requests are matched on replay by their redacted URL.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+
+	found := false
+	for _, param := range redactedQueryParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+			found = true
+		}
+	}
+	if !found {
+		return u.String()
+	}
+
+	redactedURL := *u
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.String()
+}
+
+// redactRawURL is redactURL for a recorded URL, which is kept as is when it
+// can't be parsed.
+func redactRawURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return redactURL(u)
+}
+
+// redactHeader returns a copy of the header with the values of the named headers replaced.
+func redactHeader(header http.Header, names []string) http.Header {
+	header = header.Clone()
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			for i := range values {
+				values[i] = redacted
+			}
+			header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return header
+}
+
+// redactBody replaces the values of credential fields in form encoded and
+// multipart bodies.
+func redactBody(contentType string, body []byte) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return string(body)
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return redacted
+		}
+
+		for _, field := range redactedFormFields {
+			if values.Has(field) {
+				values.Set(field, redacted)
+			}
+		}
+
+		return values.Encode()
+	case "multipart/form-data":
+		return redactMultipart(params["boundary"], body)
+	}
+
+	return string(body)
+}
+
+// redactMultipart rewrites a multipart body with the same boundary, replacing
+// the values of credential fields. Unreadable bodies are redacted entirely.
+func redactMultipart(boundary string, body []byte) string {
+	var buf bytes.Buffer
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return redacted
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return redacted
+		}
+
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return redacted
+		}
+
+		if isRedactedField(part.FormName()) {
+			_, err = io.WriteString(w, redacted)
+		} else {
+			_, err = io.Copy(w, part)
+		}
+		if err != nil {
+			return redacted
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return redacted
+	}
+
+	return buf.String()
+}
+
+func isRedactedField(name string) bool {
+	for _, field := range redactedFormFields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
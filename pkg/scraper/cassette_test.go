@@ -0,0 +1,235 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Cassette_RecordAndReplay(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/api/v1/challenges", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+		fmt.Fprint(w, `{"success": true, "data": []}`)
+	})
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "logged in")
+	})
+
+	mux.HandleFunc("/files/flag.bin", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte{0xff, 0x00, 0xfe})
+	})
+
+	file := filepath.Join(t.TempDir(), "cassette.jsonl")
+	recorder, err := NewRecordingCassette(file)
+	if err != nil {
+		t.Fatalf("NewRecordingCassette() returned error: %v", err)
+	}
+
+	client := NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.Creds.Token = "secret-token"
+	if err := client.Configure(&TransportOptions{Cassette: recorder}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	resp, err := client.GetJson("api/v1/challenges")
+	if err != nil {
+		t.Fatalf("GetJson() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	values := url.Values{"name": {"admin"}, "password": {"secret-password"}}
+	resp, err = client.Client.PostForm(server.URL+"/login", values)
+	if err != nil {
+		t.Fatalf("PostForm() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = client.GetFile("files/flag.bin")
+	if err != nil {
+		t.Fatalf("GetFile() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	recorded, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("error reading cassette: %v", err)
+	}
+
+	if lines := strings.Count(string(recorded), "\n"); lines != 3 {
+		t.Errorf("expected 3 recorded interactions, got %d", lines)
+	}
+
+	for _, secret := range []string{"secret-token", "secret-session", "secret-password"} {
+		if strings.Contains(string(recorded), secret) {
+			t.Errorf("cassette contains unredacted secret %q", secret)
+		}
+	}
+
+	// replay without the server
+	server.Close()
+
+	player, err := LoadCassette(file)
+	if err != nil {
+		t.Fatalf("LoadCassette() returned error: %v", err)
+	}
+
+	if err := client.Configure(&TransportOptions{Cassette: player}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"api/v1/challenges", `{"success": true, "data": []}`},
+		{"files/flag.bin", string([]byte{0xff, 0x00, 0xfe})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := client.GetFile(tt.path)
+			if err != nil {
+				t.Fatalf("GetFile() returned error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Errorf("got body %q, want %q", body, tt.want)
+			}
+		})
+	}
+
+	if _, err := client.GetFile("not-recorded"); err == nil {
+		t.Errorf("expected error for a request that was not recorded")
+	}
+}
+
+func Test_Cassette_RedactsMultipartAndCustomHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "cassette.jsonl")
+	recorder, err := NewRecordingCassette(file)
+	if err != nil {
+		t.Fatalf("NewRecordingCassette() returned error: %v", err)
+	}
+
+	client := NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	headers := http.Header{"X-Team-Access": {"secret-header"}}
+	if err := client.Configure(&TransportOptions{Cassette: recorder, Headers: headers}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("name", "admin")
+	_ = writer.WriteField("password", "secret-password")
+	_ = writer.Close()
+
+	resp, err := client.Client.Post(server.URL+"/register", writer.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("Post() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	recorded, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("error reading cassette: %v", err)
+	}
+
+	for _, secret := range []string{"secret-password", "secret-header"} {
+		if strings.Contains(string(recorded), secret) {
+			t.Errorf("cassette contains unredacted secret %q", secret)
+		}
+	}
+
+	// the other fields are kept
+	if !strings.Contains(string(recorded), "admin") {
+		t.Errorf("expected the name field to be recorded, got %s", recorded)
+	}
+}
+
+func Test_Cassette_RedactsFileTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "file contents")
+	}))
+
+	file := filepath.Join(t.TempDir(), "cassette.jsonl")
+	recorder, err := NewRecordingCassette(file)
+	if err != nil {
+		t.Fatalf("NewRecordingCassette() returned error: %v", err)
+	}
+
+	client := NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	if err := client.Configure(&TransportOptions{Cassette: recorder}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	resp, err := client.GetFile("files/abc/flag.zip?token=secret-file-token")
+	if err != nil {
+		t.Fatalf("GetFile() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	recorded, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("error reading cassette: %v", err)
+	}
+
+	if strings.Contains(string(recorded), "secret-file-token") {
+		t.Errorf("cassette contains unredacted file token: %s", recorded)
+	}
+
+	// replay without the server, the token of another user still matches
+	server.Close()
+
+	player, err := LoadCassette(file)
+	if err != nil {
+		t.Fatalf("LoadCassette() returned error: %v", err)
+	}
+
+	if err := client.Configure(&TransportOptions{Cassette: player}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	resp, err = client.GetFile("files/abc/flag.zip?token=other-file-token")
+	if err != nil {
+		t.Fatalf("GetFile() returned error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "file contents" {
+		t.Errorf("got body %q, want %q", body, "file contents")
+	}
+}
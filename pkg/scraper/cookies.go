@@ -0,0 +1,159 @@
+package scraper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sessionJar adds the cookies imported into the client to the cookies of the session.
+// Imported cookies survive ResetSession, session cookies with the same name take precedence.
+type sessionJar struct {
+	http.CookieJar
+	client *Client
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	cookies := j.CookieJar.Cookies(u)
+
+	seen := make(map[string]bool)
+	for _, cookie := range cookies {
+		seen[cookie.Name] = true
+	}
+
+	for _, cookie := range j.client.importedCookies(u) {
+		if !seen[cookie.Name] {
+			cookies = append(cookies, cookie)
+		}
+	}
+
+	return cookies
+}
+
+// ImportCookies adds cookies, such as a browser session or a cf_clearance cookie, to every
+// request of the client. Cookies with a domain are sent to that domain, cookies without
+// one are sent to the host of the base url. A domain with a leading dot includes its subdomains.
+//
+//	cookies, err := ParseCookieFile(file)
+//	if err != nil {
+//		fmt.Println(err)
+//	}
+//	client.ImportCookies(cookies)
+func (c *Client) ImportCookies(cookies []*http.Cookie) {
+	if c.imported == nil {
+		c.imported = newCookieJar()
+	}
+
+	for _, cookie := range cookies {
+		if cookie.Domain == "" {
+			c.hostCookies = append(c.hostCookies, cookie)
+			continue
+		}
+
+		scheme := "http"
+		if cookie.Secure {
+			scheme = "https"
+		}
+
+		u := &url.URL{Scheme: scheme, Host: strings.TrimPrefix(cookie.Domain, "."), Path: cookie.Path}
+
+		// a domain without a leading dot is host-only, the jar wants those without a domain
+		if !strings.HasPrefix(cookie.Domain, ".") {
+			hostOnly := *cookie
+			hostOnly.Domain = ""
+			cookie = &hostOnly
+		}
+
+		c.imported.SetCookies(u, []*http.Cookie{cookie})
+	}
+}
+
+// importedCookies returns the imported cookies to send with a request to u.
+func (c *Client) importedCookies(u *url.URL) []*http.Cookie {
+	var cookies []*http.Cookie
+	if c.imported != nil {
+		cookies = c.imported.Cookies(u)
+	}
+
+	if c.BaseURL != nil && strings.EqualFold(u.Hostname(), c.BaseURL.Hostname()) {
+		cookies = append(cookies, c.hostCookies...)
+	}
+
+	return cookies
+}
+
+// ParseCookieHeader takes in the value of a Cookie header, as copied from a browser, and returns its cookies.
+//
+//	cookies := ParseCookieHeader("session=abc; cf_clearance=xyz")
+func ParseCookieHeader(value string) []*http.Cookie {
+	req := &http.Request{Header: http.Header{"Cookie": {value}}}
+	return req.Cookies()
+}
+
+// ParseCookieFile reads cookies in the Netscape cookies.txt format used by curl, wget and
+// browser extensions. Lines starting with #HttpOnly_ are HttpOnly cookies, other comments
+// and blank lines are skipped.
+//
+//	# Netscape HTTP Cookie File
+//	.example.com	TRUE	/	TRUE	1700000000	cf_clearance	xyz
+func ParseCookieFile(r io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(text, "#HttpOnly_") {
+			text = strings.TrimPrefix(text, "#HttpOnly_")
+			httpOnly = true
+		}
+
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("cookie file line %d: expected 7 tab separated fields, got %d", line, len(fields))
+		}
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cookie file line %d: invalid expiry %q", line, fields[4])
+		}
+
+		cookie := &http.Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+
+		// cookies sent to subdomains have a leading dot, host-only cookies don't
+		cookie.Domain = strings.TrimPrefix(cookie.Domain, ".")
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = "." + cookie.Domain
+		}
+
+		// an expiry of 0 is a session cookie
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+
+		cookies = append(cookies, cookie)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cookies, nil
+}
@@ -0,0 +1,93 @@
+package scraper
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ParseCookieFile(t *testing.T) {
+	file := "# Netscape HTTP Cookie File\n" +
+		"\n" +
+		".example.com\tTRUE\t/\tTRUE\t1700000000\tcf_clearance\txyz\n" +
+		"#HttpOnly_ctf.example.com\tFALSE\t/\tFALSE\t0\tsession\tabc\n"
+
+	cookies, err := ParseCookieFile(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ParseCookieFile() returned error: %v", err)
+	}
+
+	want := []*http.Cookie{
+		{Domain: ".example.com", Path: "/", Secure: true, Expires: time.Unix(1700000000, 0), Name: "cf_clearance", Value: "xyz"},
+		{Domain: "ctf.example.com", Path: "/", Name: "session", Value: "abc", HttpOnly: true},
+	}
+
+	if !cmp.Equal(cookies, want) {
+		t.Errorf("got %+v, want %+v", cookies, want)
+	}
+
+	if _, err := ParseCookieFile(strings.NewReader("example.com\tTRUE\t/\n")); err == nil {
+		t.Error("expected an error for a line with missing fields")
+	}
+}
+
+func Test_ParseCookieHeader(t *testing.T) {
+	cookies := ParseCookieHeader("session=abc; cf_clearance=xyz")
+
+	var got []string
+	for _, cookie := range cookies {
+		got = append(got, cookie.Name+"="+cookie.Value)
+	}
+
+	if want := []string{"session=abc", "cf_clearance=xyz"}; !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func Test_ImportCookies(t *testing.T) {
+	client, mux, cleanup := setup()
+	defer cleanup()
+
+	var got string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Cookie")
+	})
+
+	client.ImportCookies(ParseCookieHeader("session=abc"))
+	client.ImportCookies([]*http.Cookie{{Domain: "other.example.com", Path: "/", Name: "other", Value: "nope"}})
+
+	if _, err := client.GetFile(""); err != nil {
+		t.Fatalf("GetFile() returned error: %v", err)
+	}
+
+	if got != "session=abc" {
+		t.Errorf("expected the imported cookie, got %q", got)
+	}
+
+	// imported cookies survive a session reset
+	client.ResetSession()
+
+	if _, err := client.GetFile(""); err != nil {
+		t.Fatalf("GetFile() returned error: %v", err)
+	}
+
+	if got != "session=abc" {
+		t.Errorf("expected the imported cookie after a session reset, got %q", got)
+	}
+
+	// session cookies take precedence over imported ones
+	u, _ := url.Parse(client.BaseURL.String())
+	client.Client.Jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "new"}})
+
+	if _, err := client.GetFile(""); err != nil {
+		t.Fatalf("GetFile() returned error: %v", err)
+	}
+
+	if got != "session=new" {
+		t.Errorf("expected the session cookie, got %q", got)
+	}
+}
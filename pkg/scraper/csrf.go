@@ -0,0 +1,174 @@
+package scraper
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// CSRFExtractor returns the CSRF token found in a page, or an empty string if
+// it does not recognize the page. The response body has already been parsed
+// into doc and must not be read.
+type CSRFExtractor func(doc *goquery.Document, resp *http.Response) string
+
+type namedExtractor struct {
+	name      string
+	extractor CSRFExtractor
+}
+
+var (
+	extractorsMu sync.RWMutex
+	// extractors are tried in order, the first token found wins.
+	extractors = []namedExtractor{
+		{"meta", metaCSRF},
+		{"input", inputCSRF},
+		{"script", scriptCSRF},
+		{"cookie", cookieCSRF},
+	}
+)
+
+// csrfNames are the meta tag, input and cookie names that commonly hold a CSRF token.
+var csrfNames = []string{
+	"nonce",
+	"csrf-token",
+	"csrf_token",
+	"csrf-nonce",
+	"csrfNonce",
+	"_csrf",
+	"_csrf_token",
+	"csrfmiddlewaretoken",
+	"authenticity_token",
+	"XSRF-TOKEN",
+	"csrftoken",
+}
+
+// scriptCSRFRegex matches CSRF variables in inline scripts, such as CTFd 3's
+// `'csrfNonce': "..."` and CTFd 2's `var csrf_nonce = "..."`.
+var scriptCSRFRegex = regexp.MustCompile(`(?i)['"]?(?:csrf_?nonce|csrf_?token)['"]?\s*[:=]\s*['"]([^'"\s]+)['"]`)
+
+// RegisterCSRFExtractor adds an extractor for the CSRF token of another
+// framework. Registered extractors are tried before the built-in ones,
+// registering an extractor with an existing name replaces it.
+//
+//	RegisterCSRFExtractor("rctf", func(doc *goquery.Document, resp *http.Response) string {
+//		return resp.Header.Get("X-Csrf-Token")
+//	})
+func RegisterCSRFExtractor(name string, extractor CSRFExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	for i, e := range extractors {
+		if e.name == name {
+			extractors = append(extractors[:i], extractors[i+1:]...)
+			break
+		}
+	}
+
+	extractors = append([]namedExtractor{{name, extractor}}, extractors...)
+}
+
+// ExtractCSRF takes in an http response, extracts the CSRF token from it and returns it as a string.
+// The token is looked up in meta tags, hidden inputs, inline scripts and cookies, in that order.
+// The response body is restored so it can still be read by the caller.
+//
+//	csrf := ExtractCSRF(resp)
+//	fmt.Println(csrf)
+func ExtractCSRF(resp *http.Response) string {
+	if resp == nil || resp.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	return ExtractCSRFFromDocument(doc, resp)
+}
+
+// ExtractCSRFFromDocument runs the registered extractors against an already parsed page.
+func ExtractCSRFFromDocument(doc *goquery.Document, resp *http.Response) string {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	for _, e := range extractors {
+		if token := strings.TrimSpace(e.extractor(doc, resp)); token != "" {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// metaCSRF finds tokens in tags like <meta name="csrf-token" content="...">.
+func metaCSRF(doc *goquery.Document, _ *http.Response) (token string) {
+	doc.Find("meta[name][content]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		name, _ := s.Attr("name")
+		if isCSRFName(name) {
+			token, _ = s.Attr("content")
+		}
+		return token == ""
+	})
+	return token
+}
+
+// inputCSRF finds tokens in inputs like <input type="hidden" name="nonce" value="...">,
+// regardless of the attribute order.
+func inputCSRF(doc *goquery.Document, _ *http.Response) (token string) {
+	doc.Find("input[value]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		name, _ := s.Attr("name")
+		id, _ := s.Attr("id")
+		if isCSRFName(name) || isCSRFName(id) {
+			token, _ = s.Attr("value")
+		}
+		return token == ""
+	})
+	return token
+}
+
+// scriptCSRF finds tokens assigned in inline scripts, such as CTFd's init block.
+func scriptCSRF(doc *goquery.Document, _ *http.Response) (token string) {
+	doc.Find("script").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if match := scriptCSRFRegex.FindStringSubmatch(s.Text()); len(match) == 2 {
+			token = match[1]
+		}
+		return token == ""
+	})
+	return token
+}
+
+// cookieCSRF finds tokens set as cookies, such as Django's csrftoken or XSRF-TOKEN.
+func cookieCSRF(_ *goquery.Document, resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if isCSRFName(cookie.Name) {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+func isCSRFName(name string) bool {
+	for _, csrfName := range csrfNames {
+		if strings.EqualFold(name, csrfName) {
+			return true
+		}
+	}
+	return false
+}
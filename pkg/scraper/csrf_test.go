@@ -0,0 +1,131 @@
+package scraper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func newResponse(body string, cookies ...*http.Cookie) *http.Response {
+	recorder := httptest.NewRecorder()
+	for _, cookie := range cookies {
+		http.SetCookie(recorder, cookie)
+	}
+	_, _ = recorder.WriteString(body)
+	return recorder.Result()
+}
+
+func Test_ExtractCSRF(t *testing.T) {
+	login, err := os.ReadFile("testdata/ctfd_login_full.html")
+	if err != nil {
+		t.Fatalf("error reading file: %v", err)
+	}
+
+	tests := []struct {
+		description string
+		body        string
+		cookies     []*http.Cookie
+		want        string
+	}{
+		{"no token", `<html><body></body></html>`, nil, ""},
+		{"ctfd login page", string(login), nil, "4a38d931755087a5512c817955dbb646c04adf71d36049c2d820854ffe17f7af"},
+		{
+			"meta tag",
+			`<html><head><meta name="csrf-token" content="meta123"></head></html>`,
+			nil,
+			"meta123",
+		},
+		{
+			"hidden input with attributes in any order",
+			`<html><form><input value="abc" type="hidden" name="nonce" id="nonce"></form></html>`,
+			nil,
+			"abc",
+		},
+		{
+			"ctfd 3 init script",
+			`<html><script>var init = { 'urlRoot': "", 'csrfNonce': "short123", }</script></html>`,
+			nil,
+			"short123",
+		},
+		{
+			"ctfd 2 script variable",
+			`<html><script>var csrf_nonce = "ctfd2nonce";</script></html>`,
+			nil,
+			"ctfd2nonce",
+		},
+		{
+			"cookie",
+			`<html></html>`,
+			[]*http.Cookie{{Name: "csrftoken", Value: "cookie123"}},
+			"cookie123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			resp := newResponse(tt.body, tt.cookies...)
+
+			if got := ExtractCSRF(resp); got != tt.want {
+				t.Errorf("ExtractCSRF() = %q, want %q", got, tt.want)
+			}
+
+			// the body is still readable after the extraction
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("error reading body: %v", err)
+			}
+
+			if string(body) != tt.body {
+				t.Errorf("expected body to be restored, got %q", body)
+			}
+		})
+	}
+}
+
+func Test_RegisterCSRFExtractor(t *testing.T) {
+	RegisterCSRFExtractor("test", func(doc *goquery.Document, resp *http.Response) string {
+		return doc.Find("#custom-csrf").AttrOr("data-token", "")
+	})
+	defer func() {
+		extractorsMu.Lock()
+		extractors = extractors[1:]
+		extractorsMu.Unlock()
+	}()
+
+	body := `<html><input type="hidden" name="nonce" value="builtin"><div id="custom-csrf" data-token="custom"></div></html>`
+	if got := ExtractCSRF(newResponse(body)); got != "custom" {
+		t.Errorf("expected registered extractor to take precedence, got %q", got)
+	}
+
+	body = `<html><input type="hidden" name="nonce" value="builtin"></html>`
+	if got := ExtractCSRF(newResponse(body)); got != "builtin" {
+		t.Errorf("expected built-in extractor as fallback, got %q", got)
+	}
+}
+
+func Test_FetchAndSubmitForm_CSRFHeader(t *testing.T) {
+	client, mux, cleanup := setup()
+	defer cleanup()
+
+	var csrf string
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><script>var init = {'csrfNonce': "header123"}</script><form action="/login"></form></html>`)
+	})
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		csrf = r.Header.Get("Csrf-Token")
+	})
+
+	if _, err := FetchAndSubmitForm(client.Client, client.BaseURL.String(), nil); err != nil {
+		t.Fatalf("FetchAndSubmitForm() returned error: %v", err)
+	}
+
+	if csrf != "header123" {
+		t.Errorf("expected Csrf-Token header %q, got %q", "header123", csrf)
+	}
+}
@@ -1,10 +1,14 @@
 package scraper
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
-	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -17,8 +21,35 @@ type htmlForm struct {
 	Action string
 	// Method is the HTTP method used to submit the form.
 	Method string
-	// Values are the form values.
+	// Enctype is the encoding used to submit the form.
+	Enctype string
+	// Values are the form values, including the default submit button.
 	Values url.Values
+	// Buttons are the named submit buttons of the form.
+	Buttons url.Values
+}
+
+// FormOptions selects the form submitted by FetchAndSubmitFormWithOptions and how it is submitted.
+// When several selectors are set, the form has to match all of them.
+type FormOptions struct {
+	// Selector is a CSS selector matching the form, or an element inside the form.
+	Selector string
+	// Action is a string the form action has to contain, e.g. "/login".
+	Action string
+	// Field is the name of a field the form has to contain, e.g. "password".
+	Field string
+	// Button is the name of the submit button to press instead of the first one.
+	Button string
+	// Multipart submits the form as multipart/form-data, even if the form does not ask for it.
+	Multipart bool
+	// Files are the file fields uploaded with a multipart submission.
+	Files map[string]FormFile
+}
+
+// FormFile is a file uploaded with a multipart form.
+type FormFile struct {
+	Name    string
+	Content io.Reader
 }
 
 // ParseForms takes in an html node and returns a slice of htmlForm structs.
@@ -40,49 +71,160 @@ func ParseForms(node *html.Node) (forms []htmlForm) {
 	doc := goquery.NewDocumentFromNode(node)
 	// Find all forms in the document
 	doc.Find("form").Each(func(_ int, s *goquery.Selection) {
-		form := htmlForm{
-			Values: url.Values{},
+		// Append the parsed form to the forms slice
+		forms = append(forms, parseForm(s))
+	})
+
+	return forms
+}
+
+// parseForm returns the action, method and default values of a form selection.
+func parseForm(s *goquery.Selection) htmlForm {
+	form := htmlForm{
+		Values: url.Values{},
+	}
+	// Get the form's action attribute
+	form.Action, _ = s.Attr("action")
+	// Get the form's method attribute
+	form.Method, _ = s.Attr("method")
+	// Get the form's enctype attribute
+	form.Enctype, _ = s.Attr("enctype")
+
+	// Find all input, button, select and textarea elements within the form, in document order
+	s.Find("input, button, select, textarea").Each(func(_ int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		// If the element does not have a name or is disabled, skip it
+		if name == "" {
+			return
 		}
-		// Get the form's action attribute
-		form.Action, _ = s.Attr("action")
-		// Get the form's method attribute
-		form.Method, _ = s.Attr("method")
-
-		// Find all input elements within the form
-		s.Find("input").Each(func(_ int, s *goquery.Selection) {
-			name, _ := s.Attr("name")
-			// If the input element does not have a name, skip it
-			if name == "" {
+		if _, disabled := s.Attr("disabled"); disabled {
+			return
+		}
+
+		switch goquery.NodeName(s) {
+		case "input":
+			typ, _ := s.Attr("type")
+			typ = strings.ToLower(typ)
+			value, _ := s.Attr("value")
+
+			switch typ {
+			case "radio", "checkbox":
+				if _, checked := s.Attr("checked"); !checked {
+					return
+				}
+			case "submit", "image":
+				form.addButton(name, value)
+				return
+			case "button", "reset", "file":
 				return
 			}
 
+			form.Values.Add(name, value)
+
+		case "button":
 			typ, _ := s.Attr("type")
 			typ = strings.ToLower(typ)
-			_, checked := s.Attr("checked")
-			if (typ == "radio" || typ == "checkbox") && !checked {
+			// buttons without a type are submit buttons
+			if typ != "" && typ != "submit" {
 				return
 			}
 
 			value, _ := s.Attr("value")
-			form.Values.Add(name, value)
-		})
+			form.addButton(name, value)
 
-		// Find all textarea elements within the form
-		s.Find("textarea").Each(func(_ int, s *goquery.Selection) {
-			name, _ := s.Attr("name")
-			// If the textarea element does not have a name, skip it
-			if name == "" {
-				return
+		case "select":
+			_, multiple := s.Attr("multiple")
+			options := s.Find("option")
+
+			selected := options.FilterFunction(func(_ int, option *goquery.Selection) bool {
+				_, ok := option.Attr("selected")
+				return ok
+			})
+
+			// Without a selected option, browsers submit the first option of a single select
+			if selected.Length() == 0 && !multiple {
+				selected = options.First()
 			}
 
+			selected.Each(func(_ int, option *goquery.Selection) {
+				value, ok := option.Attr("value")
+				if !ok {
+					value = strings.TrimSpace(option.Text())
+				}
+				form.Values.Add(name, value)
+			})
+
+		case "textarea":
 			value := s.Text()
 			form.Values.Add(name, value)
-		})
-		// Append the parsed form to the forms slice
-		forms = append(forms, form)
+		}
 	})
 
-	return forms
+	return form
+}
+
+// addButton records a named submit button. The first button is the default
+// one, it is submitted along with the form values.
+func (f *htmlForm) addButton(name, value string) {
+	if f.Buttons == nil {
+		f.Buttons = url.Values{}
+		f.Values.Add(name, value)
+	}
+	f.Buttons.Add(name, value)
+}
+
+// pressButton replaces the default submit button with the named one.
+func (f *htmlForm) pressButton(name string) error {
+	if _, ok := f.Buttons[name]; !ok {
+		return fmt.Errorf("no submit button named %q", name)
+	}
+
+	for button := range f.Buttons {
+		f.Values.Del(button)
+	}
+	f.Values.Set(name, f.Buttons.Get(name))
+
+	return nil
+}
+
+// selectForm returns the form of the document that matches the options, or
+// the first form when no selector is set.
+func selectForm(doc *goquery.Document, opts *FormOptions) (*goquery.Selection, error) {
+	forms := doc.Find("form")
+	if forms.Length() == 0 {
+		return nil, errors.New("no forms found")
+	}
+
+	if opts == nil {
+		return forms.First(), nil
+	}
+
+	if opts.Selector != "" {
+		// the selector can match the form itself or any element inside it
+		forms = forms.FilterSelection(doc.Find(opts.Selector).Closest("form"))
+	}
+
+	if opts.Action != "" {
+		forms = forms.FilterFunction(func(_ int, form *goquery.Selection) bool {
+			action, _ := form.Attr("action")
+			return strings.Contains(action, opts.Action)
+		})
+	}
+
+	if opts.Field != "" {
+		forms = forms.FilterFunction(func(_ int, form *goquery.Selection) bool {
+			return form.Find("input, select, textarea, button").FilterFunction(func(_ int, field *goquery.Selection) bool {
+				name, _ := field.Attr("name")
+				return name == opts.Field
+			}).Length() > 0
+		})
+	}
+
+	if forms.Length() == 0 {
+		return nil, errors.New("no form matches the selection")
+	}
+
+	return forms.First(), nil
 }
 
 // FetchAndSubmitForm takes in an http client, a url string, and a function that sets values for the form.
@@ -97,6 +239,19 @@ func ParseForms(node *html.Node) (forms []htmlForm) {
 //		fmt.Println(err)
 //	}
 func FetchAndSubmitForm(client *http.Client, urlStr string, setValues func(values url.Values)) (*http.Response, error) {
+	return FetchAndSubmitFormWithOptions(client, urlStr, setValues, nil)
+}
+
+// FetchAndSubmitFormWithOptions works like FetchAndSubmitForm, but submits the form matching the options
+// instead of the first form of the page.
+//
+//	resp, err := FetchAndSubmitFormWithOptions(client, "https://example.com/login", setValues, &FormOptions{
+//		Field: "password",
+//	})
+//	if err != nil {
+//		fmt.Println(err)
+//	}
+func FetchAndSubmitFormWithOptions(client *http.Client, urlStr string, setValues func(values url.Values), opts *FormOptions) (*http.Response, error) {
 	// Get the response from the provided url
 	resp, err := client.Get(urlStr)
 	if err != nil {
@@ -109,13 +264,28 @@ func FetchAndSubmitForm(client *http.Client, urlStr string, setValues func(value
 	if err != nil {
 		return nil, err
 	}
+	doc := goquery.NewDocumentFromNode(root)
 
-	// Get the forms from the parsed HTML
-	forms := ParseForms(root)
-	if len(forms) == 0 {
-		return nil, errors.New("no forms found")
+	// Challenges and captchas can't be solved by submitting the form
+	if err := detectProtection(doc, resp); err != nil {
+		return nil, err
+	}
+
+	// Get the form matching the options from the parsed HTML
+	selection, err := selectForm(doc, opts)
+	if err != nil {
+		if isSSOOnly(doc) {
+			return nil, ErrSSOOnly
+		}
+		return nil, err
+	}
+	form := parseForm(selection)
+
+	if opts != nil && opts.Button != "" {
+		if err := form.pressButton(opts.Button); err != nil {
+			return nil, err
+		}
 	}
-	form := forms[0]
 
 	// Resolve the action URL for the form
 	actionURL, err := url.Parse(form.Action)
@@ -133,15 +303,29 @@ func FetchAndSubmitForm(client *http.Client, urlStr string, setValues func(value
 	client.Jar.SetCookies(actionURL, resp.Cookies())
 
 	// Create a new request to submit the form
-	req, err := http.NewRequest("POST", actionURL.String(), strings.NewReader(form.Values.Encode()))
+	var req *http.Request
+	switch {
+	case strings.EqualFold(form.Method, http.MethodGet):
+		actionURL.RawQuery = form.Values.Encode()
+		req, err = http.NewRequest(http.MethodGet, actionURL.String(), nil)
+	case opts != nil && (opts.Multipart || len(opts.Files) > 0) || strings.EqualFold(form.Enctype, "multipart/form-data"):
+		var files map[string]FormFile
+		if opts != nil {
+			files = opts.Files
+		}
+		req, err = newMultipartRequest(actionURL.String(), form.Values, files)
+	default:
+		req, err = http.NewRequest(http.MethodPost, actionURL.String(), strings.NewReader(form.Values.Encode()))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
 	// Set the CSRF token in the request header
-	if csrf := ExtractCSRF(resp); csrf != "" {
+	if csrf := ExtractCSRFFromDocument(doc, resp); csrf != "" {
 		req.Header.Set("Csrf-Token", csrf)
 	}
 
@@ -154,35 +338,48 @@ func FetchAndSubmitForm(client *http.Client, urlStr string, setValues func(value
 	return resp, nil
 }
 
-// ExtractCSRF takes in an http response, extracts the CSRF token from the body of the response and returns it as a string.
-// The token is extracted using regular expressions to match specific patterns in the HTML body of the response.
-//
-//	csrf := ExtractCSRF(resp)
-//	fmt.Println(csrf)
-func ExtractCSRF(resp *http.Response) (csrf string) {
-	// Parse the HTML from the response body
-	root, err := html.Parse(resp.Body)
-	if err != nil {
-		return
+// newMultipartRequest returns a POST request with the values and files encoded as multipart/form-data.
+func newMultipartRequest(urlStr string, values url.Values, files map[string]FormFile) (*http.Request, error) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	// sort the keys so the request body is deterministic
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	// Create a goquery document from the parsed HTML
-	doc := goquery.NewDocumentFromNode(root)
+	for _, key := range keys {
+		for _, value := range values[key] {
+			if err := writer.WriteField(key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for field, file := range files {
+		part, err := writer.CreateFormFile(field, file.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if file.Content != nil {
+			if _, err := io.Copy(part, file.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-	// Compile the regex for extracting the CSRF token from the document's text
-	// 'csrfNonce': "[a-zA-Z0-9]{64}",
-	initRegex := regexp.MustCompile(`'csrfNonce': "([a-zA-Z0-9]{64})"`)
-	initToken := initRegex.FindStringSubmatch(doc.Text())
-	if len(initToken) == 2 {
-		csrf = initToken[1]
+	if err := writer.Close(); err != nil {
+		return nil, err
 	}
 
-	// <input id="nonce" name="nonce" type="hidden" value="[a-zA-Z0-9]{64}">
-	inputRegex := regexp.MustCompile(`<input id="nonce" name="nonce" type="hidden" value="([a-zA-Z0-9]{64})">`)
-	inputToken := inputRegex.FindStringSubmatch(doc.Text())
-	if len(inputToken) == 2 {
-		csrf = inputToken[1]
+	req, err := http.NewRequest(http.MethodPost, urlStr, body)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	return
+	return req, nil
 }
@@ -2,6 +2,7 @@ package scraper
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -90,6 +91,39 @@ func Test_ParseForms(t *testing.T) {
 			`<html><form><textarea name="n1">v1</textarea></form></html>`,
 			[]htmlForm{{Values: url.Values{"n1": {"v1"}}}},
 		},
+		{
+			"form with select (none selected)",
+			`<html><form><select name="n1"><option value="v1">One</option><option value="v2">Two</option></select></form></html>`,
+			[]htmlForm{{Values: url.Values{"n1": {"v1"}}}},
+		},
+		{
+			"form with select",
+			`<html><form><select name="n1"><option>v1</option><option selected>v2</option></select></form></html>`,
+			[]htmlForm{{Values: url.Values{"n1": {"v2"}}}},
+		},
+		{
+			"form with multiple select",
+			`<html><form><select name="n1" multiple><option value="v1" selected>One</option><option value="v2">Two</option><option value="v3" selected>Three</option></select></form></html>`,
+			[]htmlForm{{Values: url.Values{"n1": {"v1", "v3"}}}},
+		},
+		{
+			"form with submit buttons",
+			`<html><form enctype="multipart/form-data">
+				<button name="b1" value="v1">One</button>
+				<input type="submit" name="b2" value="v2">
+				<button type="button" name="b3" value="v3">Three</button>
+			</form></html>`,
+			[]htmlForm{{
+				Enctype: "multipart/form-data",
+				Values:  url.Values{"b1": {"v1"}},
+				Buttons: url.Values{"b1": {"v1"}, "b2": {"v2"}},
+			}},
+		},
+		{
+			"form with disabled input",
+			`<html><form><input name="n1" value="v1" disabled><input name="n2" value="v2"></form></html>`,
+			[]htmlForm{{Values: url.Values{"n2": {"v2"}}}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -171,6 +205,144 @@ func Test_FetchAndSubmitForm(t *testing.T) {
 	}
 }
 
+func Test_FetchAndSubmitFormWithOptions(t *testing.T) {
+	page := `<html>
+		<form action="/search" method="get"><input name="q" value=""></form>
+		<form action="/language"><select name="lang"><option value="en">English</option></select></form>
+		<form action="/login" id="login-form">
+			<input type="text" name="name">
+			<input type="password" name="password">
+			<button type="submit" name="_submit" value="Submit">Submit</button>
+			<button type="submit" name="_register" value="Register">Register</button>
+		</form>
+	</html>`
+
+	tests := []struct {
+		description string
+		options     *FormOptions
+		path        string
+		want        url.Values
+	}{
+		{"first form", nil, "/search", url.Values{"q": {""}}},
+		{"css selector", &FormOptions{Selector: "#login-form"}, "/login", url.Values{"name": {"test"}, "password": {""}, "_submit": {"Submit"}}},
+		{"selector inside form", &FormOptions{Selector: "select[name=lang]"}, "/language", url.Values{"lang": {"en"}}},
+		{"action", &FormOptions{Action: "login"}, "/login", url.Values{"name": {"test"}, "password": {""}, "_submit": {"Submit"}}},
+		{"field", &FormOptions{Field: "password"}, "/login", url.Values{"name": {"test"}, "password": {""}, "_submit": {"Submit"}}},
+		{"button", &FormOptions{Field: "password", Button: "_register"}, "/login", url.Values{"name": {"test"}, "password": {""}, "_register": {"Register"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			client, mux, cleanup := setup()
+			defer cleanup()
+
+			var submitted string
+			var got url.Values
+
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, page)
+			})
+
+			for _, path := range []string{"/search", "/language", "/login"} {
+				path := path
+				mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+					if err := r.ParseForm(); err != nil {
+						t.Errorf("error parsing form: %v", err)
+					}
+					submitted = path
+					got = r.Form
+				})
+			}
+
+			setValues := func(values url.Values) {
+				if values.Has("name") {
+					values.Set("name", "test")
+				}
+			}
+
+			_, err := FetchAndSubmitFormWithOptions(client.Client, client.BaseURL.String(), setValues, tt.options)
+			if err != nil {
+				t.Fatalf("error submitting form: %v", err)
+			}
+
+			if submitted != tt.path {
+				t.Errorf("expected form %q to be submitted, got %q", tt.path, submitted)
+			}
+
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_FetchAndSubmitFormWithOptions_Errors(t *testing.T) {
+	client, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><form action="/login"><input name="name"></form></html>`)
+	})
+
+	tests := []struct {
+		description string
+		options     *FormOptions
+	}{
+		{"no matching field", &FormOptions{Field: "password"}},
+		{"no matching selector", &FormOptions{Selector: "#register"}},
+		{"no matching button", &FormOptions{Button: "_submit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			_, err := FetchAndSubmitFormWithOptions(client.Client, client.BaseURL.String(), nil, tt.options)
+			if err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
+
+func Test_FetchAndSubmitForm_Multipart(t *testing.T) {
+	client, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><form action="/upload" method="post" enctype="multipart/form-data"><input name="team" value="pwners"></form></html>`)
+	})
+
+	var team, file string
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("error parsing multipart form: %v", err)
+			return
+		}
+		team = r.FormValue("team")
+
+		f, _, err := r.FormFile("avatar")
+		if err != nil {
+			t.Errorf("error reading form file: %v", err)
+			return
+		}
+		defer f.Close()
+
+		b, _ := io.ReadAll(f)
+		file = string(b)
+	})
+
+	options := &FormOptions{
+		Files: map[string]FormFile{"avatar": {Name: "avatar.png", Content: strings.NewReader("png")}},
+	}
+
+	if _, err := FetchAndSubmitFormWithOptions(client.Client, client.BaseURL.String(), nil, options); err != nil {
+		t.Fatalf("error submitting form: %v", err)
+	}
+
+	if team != "pwners" || file != "png" {
+		t.Errorf("got team %q and file %q, want %q and %q", team, file, "pwners", "png")
+	}
+}
+
 // test errors like no name for input and textarea
 func Test_ParseForms_Errors(t *testing.T) {
 	tests := []struct {
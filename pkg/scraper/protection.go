@@ -0,0 +1,116 @@
+package scraper
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	// ErrCloudflareChallenge is returned when a page is a Cloudflare challenge instead of the requested content.
+	ErrCloudflareChallenge = errors.New("Cloudflare challenge: import your browser's cf_clearance cookie with --cookie or --cookie-file, using the same --user-agent as the browser")
+	// ErrCaptcha is returned when a form is protected by a captcha.
+	ErrCaptcha = errors.New("captcha present: use --token or --cookie")
+	// ErrSSOOnly is returned when a login page only offers single sign-on.
+	ErrSSOOnly = errors.New("login is only available through SSO: use --token or --cookie")
+)
+
+// captchaSelectors match the widgets and scripts of common captcha providers.
+var captchaSelectors = []string{
+	".g-recaptcha",
+	".h-captcha",
+	".cf-turnstile",
+	"[data-sitekey]",
+	"script[src*='recaptcha']",
+	"script[src*='hcaptcha']",
+	"script[src*='turnstile']",
+}
+
+// ssoSelectors match the links of single sign-on login buttons, such as CTFd's MajorLeagueCyber login.
+var ssoSelectors = []string{
+	"a[href*='/oauth']",
+	"a[href*='/sso']",
+	"a[href*='/saml']",
+}
+
+// DetectProtection takes in an http response and returns ErrCloudflareChallenge or ErrCaptcha when
+// the page is a challenge or contains a captcha, or nil otherwise.
+// The response body is restored so it can still be read by the caller.
+//
+//	if err := DetectProtection(resp); err != nil {
+//		fmt.Println(err)
+//	}
+func DetectProtection(resp *http.Response) error {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	return detectProtection(doc, resp)
+}
+
+// detectProtection checks an already parsed page for a Cloudflare challenge or a captcha.
+func detectProtection(doc *goquery.Document, resp *http.Response) error {
+	if isCloudflareChallenge(doc, resp) {
+		return ErrCloudflareChallenge
+	}
+
+	for _, selector := range captchaSelectors {
+		if doc.Find(selector).Length() > 0 {
+			return ErrCaptcha
+		}
+	}
+
+	return nil
+}
+
+// isCloudflareChallenge reports whether the response is a Cloudflare interstitial.
+func isCloudflareChallenge(doc *goquery.Document, resp *http.Response) bool {
+	if resp != nil {
+		if resp.Header.Get("Cf-Mitigated") == "challenge" {
+			return true
+		}
+
+		if !strings.EqualFold(resp.Header.Get("Server"), "cloudflare") ||
+			(resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusServiceUnavailable) {
+			return false
+		}
+	}
+
+	if doc.Find("#challenge-form, #challenge-running, #cf-challenge-running, script[src*='challenge-platform']").Length() > 0 {
+		return true
+	}
+
+	title := strings.TrimSpace(doc.Find("title").Text())
+	return title == "Just a moment..." || title == "Attention Required! | Cloudflare"
+}
+
+// isSSOOnly reports whether a page has single sign-on links but no password field.
+func isSSOOnly(doc *goquery.Document) bool {
+	if doc.Find("input[type='password']").Length() > 0 {
+		return false
+	}
+
+	for _, selector := range ssoSelectors {
+		if doc.Find(selector).Length() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,131 @@
+package scraper
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func Test_DetectProtection(t *testing.T) {
+	tests := []struct {
+		description string
+		status      int
+		headers     map[string]string
+		body        string
+		want        error
+	}{
+		{"plain page", http.StatusOK, nil, `<html><form><input type="password" name="password"></form></html>`, nil},
+		{
+			"cloudflare interstitial",
+			http.StatusForbidden,
+			map[string]string{"Server": "cloudflare"},
+			`<html><head><title>Just a moment...</title></head></html>`,
+			ErrCloudflareChallenge,
+		},
+		{"cloudflare mitigated header", http.StatusForbidden, map[string]string{"Cf-Mitigated": "challenge"}, `<html></html>`, ErrCloudflareChallenge},
+		{
+			"cloudflare error without challenge",
+			http.StatusServiceUnavailable,
+			map[string]string{"Server": "cloudflare"},
+			`<html><head><title>Service unavailable</title></head></html>`,
+			nil,
+		},
+		{
+			"challenge title behind another server",
+			http.StatusOK,
+			nil,
+			`<html><head><title>Just a moment...</title></head></html>`,
+			nil,
+		},
+		{
+			"recaptcha",
+			http.StatusOK,
+			nil,
+			`<html><form><input type="password" name="password"><div class="g-recaptcha" data-sitekey="key"></div></form></html>`,
+			ErrCaptcha,
+		},
+		{
+			"turnstile script",
+			http.StatusOK,
+			nil,
+			`<html><head><script src="https://challenges.cloudflare.com/turnstile/v0/api.js"></script></head></html>`,
+			ErrCaptcha,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			resp := newResponse(tt.body)
+			resp.StatusCode = tt.status
+			for key, value := range tt.headers {
+				resp.Header.Set(key, value)
+			}
+
+			if err := DetectProtection(resp); !errors.Is(err, tt.want) {
+				t.Errorf("DetectProtection() = %v, want %v", err, tt.want)
+			}
+
+			// the body is still readable after the detection
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("error reading body: %v", err)
+			}
+
+			if string(body) != tt.body {
+				t.Errorf("expected body to be restored, got %q", body)
+			}
+		})
+	}
+}
+
+func Test_FetchAndSubmitForm_Protection(t *testing.T) {
+	tests := []struct {
+		description string
+		page        string
+		want        error
+	}{
+		{
+			"captcha on login form",
+			`<html><form><input name="name"><input type="password" name="password"><div class="h-captcha" data-sitekey="key"></div></form></html>`,
+			ErrCaptcha,
+		},
+		{
+			"sso only login",
+			`<html><form action="/search"><input name="q"></form><a class="btn" href="/oauth">Login with MajorLeagueCyber</a></html>`,
+			ErrSSOOnly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			client, mux, cleanup := setup()
+			defer cleanup()
+
+			mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, tt.page)
+			})
+
+			_, err := FetchAndSubmitFormWithOptions(client.Client, client.BaseURL.String()+"login", nil, &FormOptions{Field: "password"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("FetchAndSubmitFormWithOptions() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_DoRequest_CloudflareChallenge(t *testing.T) {
+	client, mux, cleanup := setup()
+	defer cleanup()
+
+	mux.HandleFunc("/api/v1/challenges", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cf-Mitigated", "challenge")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `<html><head><title>Just a moment...</title></head></html>`)
+	})
+
+	_, err := client.GetJson("api/v1/challenges")
+	if !errors.Is(err, ErrCloudflareChallenge) {
+		t.Errorf("GetJson() error = %v, want %v", err, ErrCloudflareChallenge)
+	}
+}
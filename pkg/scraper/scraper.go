@@ -19,6 +19,9 @@ type Client struct {
 	BaseURL     *url.URL     // base url of the server
 	Creds       *Credentials // credentials used for authentication
 	MaxFileSize int64        // maximum file size allowed
+
+	imported    http.CookieJar // imported cookies with a domain
+	hostCookies []*http.Cookie // imported cookies sent to the host of the base url
 }
 
 // Credentials struct stores the username and password used for authentication
@@ -36,39 +39,96 @@ type Credentials struct {
 //	client := NewClient(transport)
 func NewClient(transport http.RoundTripper) *Client {
 	// Create a new cookie jar using publicsuffix.List as the public suffix list
-	cookieJar, _ := cookiejar.New(&cookiejar.Options{
-		PublicSuffixList: publicsuffix.List,
-	})
+	cookieJar := newCookieJar()
 
 	// Check if the provided transport is nil. If it is, create a new transport with custom timeout and connection settings.
 	if transport == nil {
-		transport = &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout:   time.Duration(3 * time.Minute),
-				KeepAlive: time.Duration(15 * time.Second),
-				DualStack: true,
-			}).DialContext,
-			MaxConnsPerHost:       0,
-			MaxIdleConns:          100,
-			MaxIdleConnsPerHost:   100,
-			Proxy:                 http.ProxyFromEnvironment,
-			ExpectContinueTimeout: time.Duration(1 * time.Second),
-			TLSHandshakeTimeout:   time.Duration(10 * time.Second),
-			IdleConnTimeout:       time.Duration(90 * time.Second),
-			ResponseHeaderTimeout: time.Duration(2 * time.Minute),
-		}
+		transport = newDefaultTransport()
 	}
 
 	// Return a new client with the provided transport wrapped in a NewTransport, and the cookie jar set to the created cookie jar.
 	// Also set the Creds field to a new Credentials struct, and the MaxFileSize to 25MB
-	return &Client{
+	client := &Client{
 		Client: &http.Client{
 			Transport: NewTransport(transport),
-			Jar:       cookieJar,
 		},
 		Creds:       &Credentials{},
 		MaxFileSize: int64(1024 * 1024 * 25),
 	}
+	client.Client.Jar = &sessionJar{CookieJar: cookieJar, client: client}
+
+	return client
+}
+
+// newCookieJar returns an empty cookie jar using publicsuffix.List as the public suffix list.
+func newCookieJar() http.CookieJar {
+	cookieJar, _ := cookiejar.New(&cookiejar.Options{
+		PublicSuffixList: publicsuffix.List,
+	})
+	return cookieJar
+}
+
+// ResetSession replaces the cookie jar of the client with an empty one, dropping the current session.
+// This allows the same client to log in as another user. Imported cookies are kept.
+func (c *Client) ResetSession() {
+	c.Client.Jar = &sessionJar{CookieJar: newCookieJar(), client: c}
+}
+
+// newDefaultTransport returns an http.Transport with a long timeout and connection settings suited for scraping.
+func newDefaultTransport() *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   time.Duration(3 * time.Minute),
+			KeepAlive: time.Duration(15 * time.Second),
+			DualStack: true,
+		}).DialContext,
+		MaxConnsPerHost:       0,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   100,
+		Proxy:                 http.ProxyFromEnvironment,
+		ExpectContinueTimeout: time.Duration(1 * time.Second),
+		TLSHandshakeTimeout:   time.Duration(10 * time.Second),
+		IdleConnTimeout:       time.Duration(90 * time.Second),
+		ResponseHeaderTimeout: time.Duration(2 * time.Minute),
+	}
+}
+
+// Configure replaces the transport of the client with a default transport that has the given options applied.
+// Cookies, credentials and the base url of the client are kept.
+//
+//	err := client.Configure(&TransportOptions{Proxy: "http://127.0.0.1:8080", InsecureSkipVerify: true})
+//	if err != nil {
+//		fmt.Println(err)
+//	}
+func (c *Client) Configure(opts *TransportOptions) error {
+	transport := newDefaultTransport()
+	roundTripper := NewTransport(transport).(*roundTripper)
+
+	if err := opts.Apply(transport); err != nil {
+		return err
+	}
+
+	if opts != nil {
+		if opts.UserAgent != "" {
+			roundTripper.userAgent = opts.UserAgent
+		}
+		roundTripper.headers = opts.Headers
+
+		if opts.Cassette != nil {
+			// custom headers often carry access tokens
+			for name := range opts.Headers {
+				opts.Cassette.RedactHeaders(name)
+			}
+			roundTripper.tripper = opts.Cassette.RoundTripper(roundTripper.tripper)
+		}
+
+		if opts.Cache != nil {
+			roundTripper.tripper = opts.Cache.RoundTripper(roundTripper.tripper)
+		}
+	}
+
+	c.Client.Transport = roundTripper
+	return nil
 }
 
 // GetDoc takes in a url string and an optional list of interfaces, formats the url and sends a GET request.
@@ -208,6 +268,10 @@ func (c *Client) DoRequest(req *http.Request) (*http.Response, error) {
 		if resp.StatusCode == http.StatusOK {
 			break
 		}
+		// Challenges don't go away by retrying
+		if resp.Header.Get("Cf-Mitigated") == "challenge" {
+			break
+		}
 		resp, err = c.Client.Do(req)
 		if err != nil {
 			return nil, err
@@ -220,6 +284,10 @@ func (c *Client) DoRequest(req *http.Request) (*http.Response, error) {
 	// If the response status code is between http.StatusBadRequest and http.StatusNetworkAuthenticationRequired, return an error with the status code and text.
 	if resp.StatusCode >= http.StatusBadRequest &&
 		resp.StatusCode <= http.StatusNetworkAuthenticationRequired {
+		// Report challenges instead of a bare status code
+		if err := DetectProtection(resp); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("received status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
 	}
 
@@ -1,10 +1,204 @@
 package scraper
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 )
 
+// defaultUserAgent is the User-Agent sent when none is configured.
+const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/108.0.0.0 Safari/537.36"
+
+// TransportOptions holds the proxy, TLS and request settings applied to an http.Transport.
+type TransportOptions struct {
+	// Proxy is the URL of an HTTP(S) or SOCKS5 proxy. When empty, the proxy is taken from the environment.
+	Proxy string
+	// CAFiles are PEM encoded CA bundles trusted in addition to the system roots.
+	CAFiles []string
+	// InsecureSkipVerify disables certificate verification.
+	InsecureSkipVerify bool
+	// ClientCert and ClientKey are the PEM encoded certificate and key used for mutual TLS.
+	ClientCert string
+	ClientKey  string
+	// MinTLSVersion is the minimum TLS version to accept (1.0, 1.1, 1.2 or 1.3).
+	MinTLSVersion string
+	// UserAgent replaces the default User-Agent header.
+	UserAgent string
+	// Headers are added to every request that does not already set them.
+	Headers http.Header
+	// Resolve overrides host resolution, using curl's host:port:addr syntax.
+	Resolve []string
+	// Cassette records or replays every request made by the client.
+	Cassette *Cassette
+	// Cache stores responses on disk and revalidates them.
+	Cache *Cache
+}
+
+// Apply sets the proxy and TLS configuration of the transport according to the options.
+// The TLS configuration is added on top of the one returned by getTLSConfig().
+//
+//	transport := &http.Transport{}
+//	err := (&TransportOptions{CAFiles: []string{"ca.pem"}}).Apply(transport)
+func (o *TransportOptions) Apply(transport *http.Transport) error {
+	if o == nil {
+		return nil
+	}
+
+	if o.Proxy != "" {
+		proxyURL, err := parseProxy(o.Proxy)
+		if err != nil {
+			return err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = getTLSConfig()
+	}
+	config := transport.TLSClientConfig
+
+	if o.MinTLSVersion != "" {
+		version, err := parseTLSVersion(o.MinTLSVersion)
+		if err != nil {
+			return err
+		}
+		config.MinVersion = version
+	}
+
+	if len(o.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		for _, file := range o.CAFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read CA file: %v", err)
+			}
+
+			if !pool.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("no certificates found in CA file %q", file)
+			}
+		}
+
+		config.RootCAs = pool
+	}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		if o.ClientCert == "" || o.ClientKey == "" {
+			return fmt.Errorf("both a client certificate and a client key are required")
+		}
+
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %v", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	config.InsecureSkipVerify = o.InsecureSkipVerify
+
+	if len(o.Resolve) > 0 {
+		overrides, err := parseResolve(o.Resolve)
+		if err != nil {
+			return err
+		}
+
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+
+		transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			if override, ok := overrides[address]; ok {
+				address = override
+			}
+			return dial(ctx, network, address)
+		}
+	}
+
+	return nil
+}
+
+// parseResolve converts curl style host:port:addr entries into a map of
+// host:port to addr:port.
+//
+//	overrides, err := parseResolve([]string{"ctf.example.com:443:10.0.0.1"})
+//	fmt.Println(overrides["ctf.example.com:443"]) // Output: "10.0.0.1:443"
+func parseResolve(entries []string) (map[string]string, error) {
+	overrides := make(map[string]string)
+
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid resolve entry %q, expected host:port:addr", entry)
+		}
+
+		host, port := parts[0], parts[1]
+		addr := strings.Trim(parts[2], "[]")
+
+		if net.ParseIP(addr) == nil {
+			return nil, fmt.Errorf("invalid address %q in resolve entry %q", addr, entry)
+		}
+
+		overrides[net.JoinHostPort(host, port)] = net.JoinHostPort(addr, port)
+	}
+
+	return overrides, nil
+}
+
+// parseProxy parses a proxy URL and makes sure the scheme is supported by http.Transport.
+// A missing scheme defaults to http.
+func parseProxy(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %v", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	case "socks5h":
+		// hostnames are always resolved by the SOCKS5 proxy
+		proxyURL.Scheme = "socks5"
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL: missing host")
+	}
+
+	return proxyURL, nil
+}
+
+// parseTLSVersion converts a version string such as "1.2" to its tls constant.
+func parseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(version), "tls") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q", version)
+	}
+}
+
 // roundTripper is a struct that wraps an http.RoundTripper and adds a custom user-agent header
 // to the requests it sends.
 type roundTripper struct {
@@ -12,6 +206,8 @@ type roundTripper struct {
 	tripper http.RoundTripper
 	// userAgent is the custom user-agent header value that will be added to the requests.
 	userAgent string
+	// headers are extra headers that will be added to the requests.
+	headers http.Header
 }
 
 // NewTransport returns a new http.RoundTripper that wraps the provided http.RoundTripper
@@ -31,7 +227,7 @@ func NewTransport(tripper http.RoundTripper) http.RoundTripper {
 	// Return a new roundTripper, with the tripper field set to the provided tripper, and the userAgent field set to a default value.
 	return &roundTripper{
 		tripper:   tripper,
-		userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/108.0.0.0 Safari/537.36	",
+		userAgent: defaultUserAgent,
 	}
 }
 
@@ -60,6 +256,15 @@ func (b *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 		req.Header.Set("User-Agent", b.userAgent)
 	}
 
+	// Add the extra headers that the request does not set itself.
+	for key, values := range b.headers {
+		if req.Header.Get(key) == "" {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+
 	// Check if the tripper field of b is nil. If it is, create a new Transport with the default TLSClientConfig and use it to perform the RoundTrip.
 	if b.tripper == nil {
 		return (&http.Transport{
@@ -0,0 +1,243 @@
+package scraper
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeServerCA writes the certificate of a httptest TLS server to a PEM file.
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(file, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("error writing CA file: %v", err)
+	}
+
+	return file
+}
+
+func Test_Configure_CAFiles(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	if _, err := client.Client.Get(server.URL); err == nil {
+		t.Errorf("expected certificate error without CA file")
+	}
+
+	err := client.Configure(&TransportOptions{CAFiles: []string{writeServerCA(t, server)}})
+	if err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	resp, err := client.Client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected request to succeed with CA file: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func Test_Configure_Insecure(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	if err := client.Configure(&TransportOptions{InsecureSkipVerify: true}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	resp, err := client.Client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected request to succeed with verification disabled: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func Test_Configure_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		fmt.Fprint(w, "ok")
+	}))
+	defer proxy.Close()
+
+	client := NewClient(nil)
+	if err := client.Configure(&TransportOptions{Proxy: proxy.URL}); err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	resp, err := client.Client.Get("http://ctf.invalid/api/v1/challenges")
+	if err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if proxied != "http://ctf.invalid/api/v1/challenges" {
+		t.Errorf("expected request to go through the proxy, got %q", proxied)
+	}
+}
+
+func Test_TransportOptions_Errors(t *testing.T) {
+	tests := []struct {
+		description string
+		options     TransportOptions
+	}{
+		{"unsupported proxy scheme", TransportOptions{Proxy: "ftp://127.0.0.1:21"}},
+		{"unsupported tls version", TransportOptions{MinTLSVersion: "2.0"}},
+		{"missing CA file", TransportOptions{CAFiles: []string{"testdata/missing.pem"}}},
+		{"invalid CA file", TransportOptions{CAFiles: []string{"testdata/ctfd_login_full.html"}}},
+		{"client cert without key", TransportOptions{ClientCert: "cert.pem"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if err := tt.options.Apply(&http.Transport{}); err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
+
+func Test_TransportOptions_Apply(t *testing.T) {
+	transport := &http.Transport{}
+	options := TransportOptions{
+		Proxy:         "socks5h://127.0.0.1:9050",
+		MinTLSVersion: "1.2",
+	}
+
+	if err := options.Apply(transport); err != nil {
+		t.Fatalf("Apply() returned error: %v", err)
+	}
+
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected min version TLS 1.2, got %x", transport.TLSClientConfig.MinVersion)
+	}
+
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	proxyURL, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatalf("Proxy() returned error: %v", err)
+	}
+
+	if proxyURL.String() != "socks5://127.0.0.1:9050" {
+		t.Errorf("expected socks5 proxy, got %q", proxyURL)
+	}
+}
+
+func Test_RoundTrip_Headers(t *testing.T) {
+	tests := []struct {
+		description string
+		options     *TransportOptions
+		userAgent   string
+		header      string
+	}{
+		{"default user agent", nil, defaultUserAgent, ""},
+		{
+			"custom user agent and header",
+			&TransportOptions{
+				UserAgent: "ctftool",
+				Headers:   http.Header{"X-Team-Token": {"abc123"}},
+			},
+			"ctftool",
+			"abc123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			var userAgent, header string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				userAgent = r.Header.Get("User-Agent")
+				header = r.Header.Get("X-Team-Token")
+			}))
+			defer server.Close()
+
+			client := NewClient(nil)
+			if err := client.Configure(tt.options); err != nil {
+				t.Fatalf("Configure() returned error: %v", err)
+			}
+
+			resp, err := client.Client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if userAgent != tt.userAgent {
+				t.Errorf("expected User-Agent %q, got %q", tt.userAgent, userAgent)
+			}
+
+			if header != tt.header {
+				t.Errorf("expected X-Team-Token %q, got %q", tt.header, header)
+			}
+		})
+	}
+}
+
+func Test_Configure_Resolve(t *testing.T) {
+	var host string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	client := NewClient(nil)
+	err := client.Configure(&TransportOptions{
+		Resolve: []string{fmt.Sprintf("ctf.invalid:%s:127.0.0.1", port)},
+	})
+	if err != nil {
+		t.Fatalf("Configure() returned error: %v", err)
+	}
+
+	resp, err := client.Client.Get(fmt.Sprintf("http://ctf.invalid:%s/", port))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if host != "ctf.invalid:"+port {
+		t.Errorf("expected Host header to be kept, got %q", host)
+	}
+}
+
+func Test_parseResolve(t *testing.T) {
+	tests := []struct {
+		entry   string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"ctf.example.com:443:10.0.0.1", "ctf.example.com:443", "10.0.0.1:443", false},
+		{"ctf.example.com:80:[::1]", "ctf.example.com:80", "[::1]:80", false},
+		{"ctf.example.com:443", "", "", true},
+		{"ctf.example.com:443:not-an-ip", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			overrides, err := parseResolve([]string{tt.entry})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseResolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := overrides[tt.key]; got != tt.want {
+				t.Errorf("parseResolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}